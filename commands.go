@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"github/Arnab-cloud/tui_weather_app/internal/weather"
+	"github/Arnab-cloud/tui_weather_app/sql/schema"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runCommand handles the subcommands that work on the local database instead
+// of starting the UI.
+func runCommand(conn *sql.DB, args []string) error {
+	switch args[0] {
+	case "cache":
+		if len(args) == 2 && args[1] == "prune" {
+			return pruneCache(conn)
+		}
+		return fmt.Errorf("usage: cache prune")
+	case "cities":
+		switch {
+		case len(args) == 2 && args[1] == "learned":
+			return listLearnedCities(conn)
+		case len(args) == 3 && args[1] == "forget":
+			return forgetCity(conn, args[2])
+		}
+		return fmt.Errorf("usage: cities learned | cities forget <id>")
+	case "db":
+		if len(args) == 3 && args[1] == "migrate" {
+			return migrate(conn, args[2])
+		}
+		return fmt.Errorf("usage: db migrate up|down|status")
+	case "import-cities":
+		return importCities(conn, args[1:])
+	default:
+		return fmt.Errorf("unknown command: %s", strings.Join(args, " "))
+	}
+}
+
+func pruneCache(conn *sql.DB) error {
+	// Pruning never calls out to a provider
+	service := weather.NewWeatherService(conn, nil, GetLanguage())
+	defer service.Close()
+
+	res, err := service.Prune(context.Background(), GetRetentionPolicy())
+	if err != nil {
+		return fmt.Errorf("pruning the cache: %w", err)
+	}
+
+	fmt.Printf("Removed %d weather rows and %d forecast rows, reclaimed %s\n",
+		res.WeatherRows, res.ForecastRows, formatBytes(res.BytesReclaimed))
+	return nil
+}
+
+func listLearnedCities(conn *sql.DB) error {
+	service := weather.NewWeatherService(conn, nil, GetLanguage())
+	defer service.Close()
+
+	cities, err := service.LearnedCities(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(cities) == 0 {
+		fmt.Println("No learned cities")
+		return nil
+	}
+	for _, city := range cities {
+		name := city.Name
+		if city.State != "" {
+			name += ", " + city.State
+		}
+		fmt.Printf("%-20d %-40s %-3s %9.4f %9.4f  %s\n",
+			city.ID, name, city.Country, city.Lat, city.Lon, city.CreatedAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+func forgetCity(conn *sql.DB, arg string) error {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("not a city id: %s", arg)
+	}
+
+	service := weather.NewWeatherService(conn, nil, GetLanguage())
+	defer service.Close()
+
+	removed, err := service.ForgetCity(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("no learned city with id %d", id)
+	}
+	fmt.Printf("Forgot city %d\n", id)
+	return nil
+}
+
+func migrate(conn *sql.DB, direction string) error {
+	ctx := context.Background()
+	migrator, err := database.NewMigrator(conn, schema.FS)
+	if err != nil {
+		return err
+	}
+
+	switch direction {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migrations\n", applied)
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if rolledBack == nil {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		fmt.Printf("Rolled back %s\n", rolledBack.Name)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt.Valid {
+				applied = time.Unix(st.AppliedAt.Int64, 0).Format(time.DateTime)
+			}
+			fmt.Printf("%-40s %s\n", st.Name, applied)
+		}
+
+	default:
+		return fmt.Errorf("usage: db migrate up|down|status")
+	}
+	return nil
+}
+
+// maxSkippedShown caps how many skipped records import-cities lists.
+const maxSkippedShown = 20
+
+func importCities(conn *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("import-cities", flag.ContinueOnError)
+	batchSize := flags.Int("batch", 1000, "cities per transaction")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *batchSize < 1 {
+		return fmt.Errorf("usage: import-cities [-batch n] <city.list.json[.gz] | cities*.txt[.gz]> (n at least 1)")
+	}
+
+	seeder := database.NewCitySeeder(conn)
+	stats, err := seeder.ImportFile(context.Background(), flags.Arg(0), *batchSize, func(s database.ImportStats) {
+		fmt.Fprintf(os.Stderr, "\rImported %d cities (%d%%)", s.Imported, s.BytesRead*100/max(s.TotalBytes, 1))
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("importing cities: %w (run the same command again to resume)", err)
+	}
+
+	if stats.Resumed > 0 {
+		fmt.Printf("Resumed after %d records done by an earlier run\n", stats.Resumed)
+	}
+	fmt.Printf("Imported %d cities, skipped %d invalid records\n", stats.Imported, len(stats.Skipped))
+	for i, skipped := range stats.Skipped {
+		if i == maxSkippedShown {
+			fmt.Printf("  ...and %d more\n", len(stats.Skipped)-maxSkippedShown)
+			break
+		}
+		fmt.Printf("  record %d: %s\n", skipped.Record, skipped.Reason)
+	}
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
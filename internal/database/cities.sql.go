@@ -7,20 +7,23 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createCity = `-- name: CreateCity :one
-INSERT INTO cities (id, name, country, lat, lon)
-VALUES (?, ?, ?, ?, ?)
-RETURNING id, name, country, lat, lon, created_at
+INSERT INTO cities (id, name, country, lat, lon, population, search_name)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, country, lat, lon, created_at, population, search_name, source, state
 `
 
 type CreateCityParams struct {
-	ID      int64
-	Name    string
-	Country string
-	Lat     float64
-	Lon     float64
+	ID         int64
+	Name       string
+	Country    string
+	Lat        float64
+	Lon        float64
+	Population sql.NullInt64
+	SearchName string
 }
 
 func (q *Queries) CreateCity(ctx context.Context, arg CreateCityParams) (City, error) {
@@ -30,6 +33,8 @@ func (q *Queries) CreateCity(ctx context.Context, arg CreateCityParams) (City, e
 		arg.Country,
 		arg.Lat,
 		arg.Lon,
+		arg.Population,
+		arg.SearchName,
 	)
 	var i City
 	err := row.Scan(
@@ -39,6 +44,10 @@ func (q *Queries) CreateCity(ctx context.Context, arg CreateCityParams) (City, e
 		&i.Lat,
 		&i.Lon,
 		&i.CreatedAt,
+		&i.Population,
+		&i.SearchName,
+		&i.Source,
+		&i.State,
 	)
 	return i, err
 }
@@ -53,8 +62,84 @@ func (q *Queries) DeleteCity(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteCityBySource = `-- name: DeleteCityBySource :execrows
+DELETE FROM cities
+WHERE id = ?
+  AND source = ?
+`
+
+type DeleteCityBySourceParams struct {
+	ID     int64
+	Source string
+}
+
+func (q *Queries) DeleteCityBySource(ctx context.Context, arg DeleteCityBySourceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCityBySource, arg.ID, arg.Source)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const findCitiesInBox = `-- name: FindCitiesInBox :many
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
+FROM cities
+WHERE lat >= ? AND lat <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
+`
+
+type FindCitiesInBoxParams struct {
+	Lat   float64
+	Lat_2 float64
+	Lon   float64
+	Lon_2 float64
+	Lon_3 float64
+	Lon_4 float64
+}
+
+func (q *Queries) FindCitiesInBox(ctx context.Context, arg FindCitiesInBoxParams) ([]City, error) {
+	rows, err := q.db.QueryContext(ctx, findCitiesInBox,
+		arg.Lat,
+		arg.Lat_2,
+		arg.Lon,
+		arg.Lon_2,
+		arg.Lon_3,
+		arg.Lon_4,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findCity = `-- name: FindCity :many
-SELECT id, name, country, lat, lon, created_at
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
 FROM cities
 WHERE LOWER(name)=LOWER(?)
 `
@@ -75,6 +160,10 @@ func (q *Queries) FindCity(ctx context.Context, lower string) ([]City, error) {
 			&i.Lat,
 			&i.Lon,
 			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
 		); err != nil {
 			return nil, err
 		}
@@ -90,7 +179,7 @@ func (q *Queries) FindCity(ctx context.Context, lower string) ([]City, error) {
 }
 
 const findCityWithID = `-- name: FindCityWithID :one
-SELECT id, name, country, lat, lon, created_at
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
 FROM cities
 WHERE id = ?
 `
@@ -105,18 +194,136 @@ func (q *Queries) FindCityWithID(ctx context.Context, id int64) (City, error) {
 		&i.Lat,
 		&i.Lon,
 		&i.CreatedAt,
+		&i.Population,
+		&i.SearchName,
+		&i.Source,
+		&i.State,
 	)
 	return i, err
 }
 
 const fuzzYFindCity = `-- name: FuzzYFindCity :many
-SELECT id, name, country, lat, lon, created_at
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
+FROM cities
+WHERE search_name LIKE ?
+`
+
+func (q *Queries) FuzzYFindCity(ctx context.Context, searchName string) ([]City, error) {
+	rows, err := q.db.QueryContext(ctx, fuzzYFindCity, searchName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertCityIfMissing = `-- name: InsertCityIfMissing :execrows
+INSERT INTO cities (id, name, country, state, lat, lon, search_name, source)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO NOTHING
+`
+
+type InsertCityIfMissingParams struct {
+	ID         int64
+	Name       string
+	Country    string
+	State      string
+	Lat        float64
+	Lon        float64
+	SearchName string
+	Source     string
+}
+
+func (q *Queries) InsertCityIfMissing(ctx context.Context, arg InsertCityIfMissingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertCityIfMissing,
+		arg.ID,
+		arg.Name,
+		arg.Country,
+		arg.State,
+		arg.Lat,
+		arg.Lon,
+		arg.SearchName,
+		arg.Source,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listCities = `-- name: ListCities :many
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
+FROM cities
+`
+
+func (q *Queries) ListCities(ctx context.Context) ([]City, error) {
+	rows, err := q.db.QueryContext(ctx, listCities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCitiesBySource = `-- name: ListCitiesBySource :many
+SELECT id, name, country, lat, lon, created_at, population, search_name, source, state
 FROM cities
-WHERE name like ?
+WHERE source = ?
+ORDER BY created_at DESC, id
 `
 
-func (q *Queries) FuzzYFindCity(ctx context.Context, name string) ([]City, error) {
-	rows, err := q.db.QueryContext(ctx, fuzzYFindCity, name)
+func (q *Queries) ListCitiesBySource(ctx context.Context, source string) ([]City, error) {
+	rows, err := q.db.QueryContext(ctx, listCitiesBySource, source)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +338,10 @@ func (q *Queries) FuzzYFindCity(ctx context.Context, name string) ([]City, error
 			&i.Lat,
 			&i.Lon,
 			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
 		); err != nil {
 			return nil, err
 		}
@@ -144,3 +355,143 @@ func (q *Queries) FuzzYFindCity(ctx context.Context, name string) ([]City, error
 	}
 	return items, nil
 }
+
+const listCitiesMissingSearchName = `-- name: ListCitiesMissingSearchName :many
+SELECT id, name
+FROM cities
+WHERE search_name = ''
+LIMIT ?
+`
+
+type ListCitiesMissingSearchNameRow struct {
+	ID   int64
+	Name string
+}
+
+func (q *Queries) ListCitiesMissingSearchName(ctx context.Context, limit int64) ([]ListCitiesMissingSearchNameRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCitiesMissingSearchName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCitiesMissingSearchNameRow
+	for rows.Next() {
+		var i ListCitiesMissingSearchNameRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchCities = `-- name: SearchCities :many
+SELECT cities.id, cities.name, cities.country, cities.lat, cities.lon, cities.created_at, cities.population, cities.search_name, cities.source, cities.state
+FROM cities_fts
+JOIN cities ON cities.id = cities_fts.rowid
+WHERE cities_fts MATCH CAST(?1 AS TEXT)
+ORDER BY cities_fts.rank
+LIMIT ?2
+`
+
+type SearchCitiesParams struct {
+	Query      string
+	MaxResults int64
+}
+
+func (q *Queries) SearchCities(ctx context.Context, arg SearchCitiesParams) ([]City, error) {
+	rows, err := q.db.QueryContext(ctx, searchCities, arg.Query, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.CreatedAt,
+			&i.Population,
+			&i.SearchName,
+			&i.Source,
+			&i.State,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setCitySearchName = `-- name: SetCitySearchName :exec
+UPDATE cities
+SET search_name = ?
+WHERE id = ?
+`
+
+type SetCitySearchNameParams struct {
+	SearchName string
+	ID         int64
+}
+
+func (q *Queries) SetCitySearchName(ctx context.Context, arg SetCitySearchNameParams) error {
+	_, err := q.db.ExecContext(ctx, setCitySearchName, arg.SearchName, arg.ID)
+	return err
+}
+
+const upsertCity = `-- name: UpsertCity :exec
+INSERT INTO cities (id, name, country, state, lat, lon, population, search_name, source)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    country = excluded.country,
+    lat = excluded.lat,
+    lon = excluded.lon,
+    population = excluded.population,
+    search_name = excluded.search_name,
+    source = excluded.source,
+    state = COALESCE(NULLIF(excluded.state, ''), state)
+`
+
+type UpsertCityParams struct {
+	ID         int64
+	Name       string
+	Country    string
+	State      string
+	Lat        float64
+	Lon        float64
+	Population sql.NullInt64
+	SearchName string
+	Source     string
+}
+
+func (q *Queries) UpsertCity(ctx context.Context, arg UpsertCityParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCity,
+		arg.ID,
+		arg.Name,
+		arg.Country,
+		arg.State,
+		arg.Lat,
+		arg.Lon,
+		arg.Population,
+		arg.SearchName,
+		arg.Source,
+	)
+	return err
+}
@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	gooseUp   = "-- +goose Up"
+	gooseDown = "-- +goose Down"
+)
+
+// Migration is one file of sql/schema, split at its goose annotations.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt sql.NullInt64
+}
+
+// legacyProbes recognise the migrations that were baked into the shipped
+// database, or applied to it by hand, before applied versions were tracked.
+var legacyProbes = map[int64]string{
+	1: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'cities'`,
+	2: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'city_name_idx'`,
+	3: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'weather_cache'`,
+	4: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_weather_cid'`,
+	5: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'city_name_idx' AND sql LIKE '%NOCASE%'`,
+	6: `SELECT COUNT(*) FROM pragma_table_info('weather_cache') WHERE name = 'weather_id'`,
+	7: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'forecast_cache'`,
+	8: `SELECT COUNT(*) FROM pragma_table_info('weather_cache') WHERE name = 'provider'`,
+	9: `SELECT COUNT(*) FROM pragma_table_info('forecast_cache') WHERE name = 'lang'`,
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator reads the migrations in fsys, named like 001_cities.sql.
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		m, err := parseMigration(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func parseMigration(fsys fs.FS, file string) (Migration, error) {
+	name := strings.TrimSuffix(path.Base(file), ".sql")
+	prefix, _, _ := strings.Cut(name, "_")
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return Migration{}, fmt.Errorf("migration %s: name must start with a version number", file)
+	}
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	_, rest, ok := strings.Cut(string(content), gooseUp)
+	if !ok {
+		return Migration{}, fmt.Errorf("migration %s: missing %q", file, gooseUp)
+	}
+	up, down, _ := strings.Cut(rest, gooseDown)
+
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      strings.TrimSpace(up),
+		Down:    strings.TrimSpace(down),
+	}, nil
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	var tracked int
+	err := m.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+	).Scan(&tracked)
+	if err != nil || tracked > 0 {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `CREATE TABLE schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at INTEGER NOT NULL
+)`)
+	if err != nil {
+		return err
+	}
+
+	// A database from before versions were tracked already has some of the
+	// early migrations; record those instead of running them again.
+	for _, mig := range m.migrations {
+		probe, ok := legacyProbes[mig.Version]
+		if !ok {
+			break
+		}
+		var found int
+		if err := tx.QueryRowContext(ctx, probe).Scan(&found); err != nil {
+			return err
+		}
+		if found == 0 {
+			break
+		}
+		if err := recordMigration(ctx, tx, mig.Version); err != nil {
+			return err
+		}
+		log.Printf("found existing schema for migration %s", mig.Name)
+	}
+
+	return tx.Commit()
+}
+
+func recordMigration(ctx context.Context, tx *sql.Tx, version int64) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		version, time.Now().Unix(),
+	)
+	return err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]int64)
+	for rows.Next() {
+		var version, appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i].Migration = mig
+		if at, ok := applied[mig.Version]; ok {
+			statuses[i].AppliedAt = sql.NullInt64{Int64: at, Valid: true}
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, st := range statuses {
+		if st.AppliedAt.Valid {
+			continue
+		}
+		if err := m.apply(ctx, st.Version, st.Up, recordMigration); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", st.Name, err)
+		}
+		log.Printf("applied migration %s", st.Name)
+		applied++
+	}
+	return applied, nil
+}
+
+// Down rolls back the most recently applied migration and returns it, or
+// nil when nothing is applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		st := statuses[i]
+		if !st.AppliedAt.Valid {
+			continue
+		}
+		err := m.apply(ctx, st.Version, st.Down, func(ctx context.Context, tx *sql.Tx, version int64) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", st.Name, err)
+		}
+		log.Printf("rolled back migration %s", st.Name)
+		return &st.Migration, nil
+	}
+	return nil, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, version int64, script string, record func(context.Context, *sql.Tx, int64) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if script != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+	if err := record(ctx, tx, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github/Arnab-cloud/tui_weather_app/sql/schema"
+)
+
+func newTestMigrator(t *testing.T) (*sql.DB, *Migrator) {
+	t.Helper()
+	conn, err := Open(filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m, err := NewMigrator(conn, schema.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, m
+}
+
+// applyByHand runs the Up scripts of the migrations up to and including
+// version without recording them, the way the database was built before
+// versions were tracked.
+func applyByHand(t *testing.T, conn *sql.DB, m *Migrator, version int64) {
+	t.Helper()
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			break
+		}
+		if _, err := conn.Exec(mig.Up); err != nil {
+			t.Fatalf("migration %s: %s", mig.Name, err)
+		}
+	}
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int64 {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []int64
+	for _, st := range statuses {
+		if st.AppliedAt.Valid {
+			applied = append(applied, st.Version)
+		}
+	}
+	return applied
+}
+
+func TestMigratorAdoptsUntrackedSchema(t *testing.T) {
+	// Databases from before tracking: the shipped one, and ones the forecast,
+	// provider and lang migrations were applied to by hand
+	tests := []struct {
+		name    string
+		version int64
+	}{
+		{"cities", 2},
+		{"weather cache", 4},
+		{"shipped", 6},
+		{"forecast cache", 7},
+		{"provider", 8},
+		{"lang", 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			conn, m := newTestMigrator(t)
+			applyByHand(t, conn, m, tt.version)
+
+			applied, err := m.Up(ctx)
+			if err != nil {
+				t.Fatalf("Up on a database at version %d: %s", tt.version, err)
+			}
+			if want := len(m.migrations) - int(tt.version); applied != want {
+				t.Errorf("Up applied %d migrations, want %d", applied, want)
+			}
+			if got := appliedVersions(t, m); len(got) != len(m.migrations) {
+				t.Errorf("applied versions = %v, want all %d", got, len(m.migrations))
+			}
+		})
+	}
+}
+
+func TestMigratorRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, m := newTestMigrator(t)
+
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Fatalf("a new database has %v applied", got)
+	}
+
+	for round := range 2 {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			t.Fatalf("round %d: Up: %s", round, err)
+		}
+		if applied != len(m.migrations) {
+			t.Errorf("round %d: Up applied %d migrations, want %d", round, applied, len(m.migrations))
+		}
+		if got := appliedVersions(t, m); len(got) != len(m.migrations) {
+			t.Errorf("round %d: applied versions = %v, want all %d", round, got, len(m.migrations))
+		}
+		if applied, err := m.Up(ctx); err != nil || applied != 0 {
+			t.Errorf("round %d: Up again applied %d, %v; want nothing", round, applied, err)
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig, err := m.Down(ctx)
+			if err != nil {
+				t.Fatalf("round %d: Down: %s", round, err)
+			}
+			if mig == nil || mig.Version != m.migrations[i].Version {
+				t.Fatalf("round %d: Down rolled back %v, want version %d", round, mig, m.migrations[i].Version)
+			}
+			if got := appliedVersions(t, m); len(got) != i {
+				t.Fatalf("round %d: after rolling back %s, applied = %v", round, mig.Name, got)
+			}
+		}
+		if mig, err := m.Down(ctx); mig != nil || err != nil {
+			t.Errorf("round %d: Down with nothing applied = %v, %v", round, mig, err)
+		}
+	}
+}
+
+func TestMigratorUpgradesBareSchema(t *testing.T) {
+	// The schema the app started out with: cities and the weather cache,
+	// with rows in them and nothing recording which migrations made them
+	ctx := context.Background()
+	conn, m := newTestMigrator(t)
+	applyByHand(t, conn, m, 4)
+	_, err := conn.Exec(`INSERT INTO cities (id, name, country, lat, lon) VALUES (2988507, 'Paris', 'FR', 48.8534, 2.3488)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(`INSERT INTO weather_cache (city_id, city_name, lat, lon, temp, fetched_at) VALUES (2988507, 'Paris', 48.8534, 2.3488, 21.5, 1700000000)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up on the bare schema: %s", err)
+	}
+	if got := appliedVersions(t, m); len(got) != len(m.migrations) {
+		t.Errorf("applied versions = %v, want all %d", got, len(m.migrations))
+	}
+
+	// Read back through the queries, which expect every later column
+	q := New(conn)
+	cities, err := q.ListCities(ctx)
+	if err != nil || len(cities) != 1 || cities[0].Name != "Paris" {
+		t.Errorf("cities after the upgrade = %v, %v; want Paris", cities, err)
+	}
+	w, err := q.GetLatestWeatherByCityID(ctx, sql.NullInt64{Int64: 2988507, Valid: true})
+	if err != nil || w.Temp.Float64 != 21.5 {
+		t.Errorf("cached weather after the upgrade = %+v, %v", w, err)
+	}
+}
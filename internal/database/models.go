@@ -10,14 +10,77 @@ import (
 )
 
 type City struct {
+	ID         int64
+	Name       string
+	Country    string
+	Lat        float64
+	Lon        float64
+	CreatedAt  time.Time
+	Population sql.NullInt64
+	SearchName string
+	Source     string
+	State      string
+}
+
+type CityImport struct {
+	Source      string
+	Size        int64
+	RecordsDone int64
+	UpdatedAt   int64
+}
+
+type ForecastCache struct {
+	ID           int64
+	CityID       sql.NullInt64
+	CityName     sql.NullString
+	Country      sql.NullString
+	Lat          sql.NullFloat64
+	Lon          sql.NullFloat64
+	Timezone     sql.NullInt64
+	ForecastTime sql.NullInt64
+	WeatherMain  sql.NullString
+	WeatherDesc  sql.NullString
+	WeatherIcon  sql.NullString
+	WeatherID    sql.NullInt64
+	Temp         sql.NullFloat64
+	FeelsLike    sql.NullFloat64
+	TempMin      sql.NullFloat64
+	TempMax      sql.NullFloat64
+	Humidity     sql.NullInt64
+	Pressure     sql.NullInt64
+	WindSpeed    sql.NullFloat64
+	WindDeg      sql.NullInt64
+	WindGust     sql.NullFloat64
+	Pop          sql.NullFloat64
+	Rain3h       sql.NullFloat64
+	Cloudiness   sql.NullInt64
+	Visibility   sql.NullInt64
+	FetchedAt    sql.NullInt64
+	Lang         sql.NullString
+}
+
+type SavedLocation struct {
 	ID        int64
 	Name      string
 	Country   string
+	State     string
 	Lat       float64
 	Lon       float64
+	Position  int64
 	CreatedAt time.Time
 }
 
+type SearchHistory struct {
+	CityID     int64
+	Name       string
+	Country    string
+	State      string
+	Lat        float64
+	Lon        float64
+	Count      int64
+	LastUsedAt int64
+}
+
 type WeatherCache struct {
 	ID          int64
 	CityID      sql.NullInt64
@@ -48,4 +111,6 @@ type WeatherCache struct {
 	GroundLevel sql.NullInt64
 	Sunrise     sql.NullInt64
 	Sunset      sql.NullInt64
+	Provider    sql.NullString
+	Lang        sql.NullString
 }
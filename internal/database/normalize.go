@@ -0,0 +1,25 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var folder = cases.Fold()
+
+// NormalizeName folds a place name for matching: decomposed (NFKD), stripped
+// of combining marks and case folded, so "Zürich" and "ZURICH" both become
+// "zurich". It is what the cities.search_name column holds.
+func NormalizeName(name string) string {
+	stripMarks := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(stripMarks, name)
+	if err != nil {
+		stripped = name
+	}
+	return folder.String(strings.TrimSpace(stripped))
+}
@@ -0,0 +1,41 @@
+package database
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Paris", "paris"},
+		{"  New York  ", "new york"},
+		{"São Paulo", "sao paulo"},
+		{"Zürich", "zurich"},
+		{"ZÜRICH", "zurich"},
+		{"Zu\u0308rich", "zurich"},
+		{"Straße", "strasse"},
+		{"STRASSE", "strasse"},
+		{"Kraków", "krakow"},
+		{"Ålesund", "alesund"},
+		{"Ciudad de México", "ciudad de mexico"},
+		{"İstanbul", "istanbul"},
+
+		// Compatibility forms (NFKD)
+		{"ﬁfe", "fife"},
+		{"ﬂorence", "florence"},
+		{"Ǆakovo", "dzakovo"},
+		{"Ｔｏｋｙｏ", "tokyo"},
+		{"Rome\u00a0City", "rome city"},
+
+		// Letters that are not a base letter with a mark stay as they are
+		{"Łódź", "łodz"},
+		{"Ærøskøbing", "ærøskøbing"},
+		{"Москва", "москва"},
+		{"東京", "東京"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.in); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
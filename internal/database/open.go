@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// MaxOpenConns caps the pool. WAL lets readers run next to the one writer
+// SQLite allows, so a few connections are enough.
+const MaxOpenConns = 4
+
+// BusyTimeoutMs is how long a statement waits for another connection, or
+// another instance of the app, to release its lock before giving up.
+const BusyTimeoutMs = 5000
+
+// Open opens the SQLite database at path with WAL, a busy timeout and
+// foreign keys turned on for every connection in the pool.
+func Open(path string) (*sql.DB, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	pragmas.Add("_pragma", "synchronous(NORMAL)")
+	pragmas.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", BusyTimeoutMs))
+	pragmas.Add("_pragma", "foreign_keys(1)")
+
+	db, err := sql.Open("sqlite", path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(MaxOpenConns)
+	db.SetMaxIdleConns(MaxOpenConns)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -1,11 +1,28 @@
 package database
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values of cities.source
+const (
+	CitySourceBundled  = "bundled"
+	CitySourceImport   = "import"
+	CitySourceGeocoded = "geocoded"
 )
 
 type CitySeeder struct {
@@ -14,15 +31,44 @@ type CitySeeder struct {
 }
 
 type cityEntry struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	Country string `json:"country"`
-	Coord   struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Country    string `json:"country"`
+	State      string `json:"state"`
+	Population int64  `json:"population"`
+	Coord      struct {
 		Lat float64 `json:"lat"`
 		Lon float64 `json:"lon"`
 	} `json:"coord"`
 }
 
+// ImportStats is how far an import got. Records counts every record read
+// from the file, valid or not.
+type ImportStats struct {
+	Records    int
+	Imported   int
+	Resumed    int
+	Skipped    []SkippedRecord
+	BytesRead  int64
+	TotalBytes int64
+}
+
+// SkippedRecord is a record that was left out because it made no sense.
+// Record is its 1-based position in the file.
+type SkippedRecord struct {
+	Record int
+	Reason string
+}
+
+// errInvalidRecord marks a record that can be skipped, as opposed to a file
+// that can't be read any further.
+var errInvalidRecord = errors.New("invalid record")
+
+// cityReader reads city records one at a time from one input format.
+type cityReader interface {
+	next() (UpsertCityParams, error)
+}
+
 func NewCitySeeder(db *sql.DB) *CitySeeder {
 	return &CitySeeder{
 		db:      db,
@@ -31,53 +77,101 @@ func NewCitySeeder(db *sql.DB) *CitySeeder {
 }
 
 func (s *CitySeeder) LoadCitiesFromFile(ctx context.Context, filePath string, batchSize int) error {
+	_, err := s.ImportFile(ctx, filePath, batchSize, nil)
+	return err
+}
+
+// ImportFile upserts the cities in an OpenWeather city.list.json or a
+// GeoNames cities*.txt dump, either of them optionally gzipped. Progress is
+// saved with every batch, so an interrupted import of the same file picks up
+// where it stopped. progress, if set, is called after every batch.
+func (s *CitySeeder) ImportFile(ctx context.Context, filePath string, batchSize int, progress func(ImportStats)) (ImportStats, error) {
+	var stats ImportStats
+	if batchSize < 1 {
+		return stats, fmt.Errorf("batch size must be at least 1, got %d", batchSize)
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("Error opening city file %s", err)
+		return stats, fmt.Errorf("Error opening city file %s", err)
 	}
 	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	info, err := file.Stat()
+	if err != nil {
+		return stats, err
+	}
+	stats.TotalBytes = info.Size()
 
-	if _, err := decoder.Token(); err != nil {
-		return fmt.Errorf("Error reading JSON start token: %s", err)
+	source, err := filepath.Abs(filePath)
+	if err != nil {
+		return stats, err
 	}
 
-	batch := make([]CreateCityParams, 0, batchSize)
-	count := 0
+	resumeFrom := 0
+	if prev, err := s.queries.GetCityImport(ctx, source); err == nil && prev.Size == info.Size() {
+		resumeFrom = int(prev.RecordsDone)
+	}
 
-	for decoder.More() {
-		var entry cityEntry
+	counter := &countingReader{r: file}
+	reader, err := newCityReader(counter)
+	if err != nil {
+		return stats, err
+	}
 
-		if err := decoder.Decode(&entry); err != nil {
-			return fmt.Errorf("Decode Error at record %d: %w", count, err)
+	batch := make([]UpsertCityParams, 0, batchSize)
+	flush := func() error {
+		if err := s.upsertBatch(ctx, batch, source, info.Size(), stats.Records); err != nil {
+			return err
+		}
+		stats.Imported += len(batch)
+		stats.BytesRead = counter.n
+		batch = batch[:0]
+		if progress != nil {
+			progress(stats)
 		}
+		return nil
+	}
 
-		batch = append(batch, CreateCityParams{
-			ID:      entry.ID,
-			Name:    entry.Name,
-			Country: entry.Country,
-			Lat:     entry.Coord.Lat,
-			Lon:     entry.Coord.Lon,
-		})
+	for {
+		city, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		stats.Records++
 
+		if stats.Records <= resumeFrom {
+			stats.Resumed++
+			continue
+		}
+		if errors.Is(err, errInvalidRecord) {
+			stats.Skipped = append(stats.Skipped, SkippedRecord{Record: stats.Records, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return stats, fmt.Errorf("Decode Error at record %d: %w", stats.Records, err)
+		}
+
+		batch = append(batch, city)
 		if len(batch) >= batchSize {
-			if err := s.insertBatch(ctx, batch); err != nil {
-				return err
+			if err := flush(); err != nil {
+				return stats, err
 			}
-			batch = batch[:0]
 		}
-		count++
 	}
 
 	if len(batch) > 0 {
-		return s.insertBatch(ctx, batch)
+		if err := flush(); err != nil {
+			return stats, err
+		}
 	}
+	stats.BytesRead = counter.n
 
-	return nil
+	// Done; a later run of the same file starts over
+	return stats, s.queries.DeleteCityImport(ctx, source)
 }
 
-func (s *CitySeeder) insertBatch(ctx context.Context, batch []CreateCityParams) error {
+func (s *CitySeeder) upsertBatch(ctx context.Context, batch []UpsertCityParams, source string, size int64, recordsDone int) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -87,10 +181,202 @@ func (s *CitySeeder) insertBatch(ctx context.Context, batch []CreateCityParams)
 	qtx := s.queries.WithTx(tx)
 
 	for _, city := range batch {
-		if _, err := qtx.CreateCity(ctx, city); err != nil {
+		if err := qtx.UpsertCity(ctx, city); err != nil {
 			return fmt.Errorf("failed to insert city %s: %w", city.Name, err)
 		}
 	}
 
+	err = qtx.SaveCityImport(ctx, SaveCityImportParams{
+		Source:      source,
+		Size:        size,
+		RecordsDone: int64(recordsDone),
+		UpdatedAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newCityReader sniffs the input: gzip by its magic bytes, then JSON if it
+// starts with an array and GeoNames TSV otherwise.
+func newCityReader(r io.Reader) (cityReader, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		buffered = bufio.NewReader(gz)
+	}
+
+	head, _ := buffered.Peek(512)
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("[")) {
+		decoder := json.NewDecoder(buffered)
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("Error reading JSON start token: %s", err)
+		}
+		return &jsonCityReader{decoder: decoder}, nil
+	}
+
+	scanner := bufio.NewScanner(buffered)
+	// alternatenames can make GeoNames lines very long
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	return &geonamesCityReader{scanner: scanner}, nil
+}
+
+type jsonCityReader struct {
+	decoder *json.Decoder
+}
+
+func (j *jsonCityReader) next() (UpsertCityParams, error) {
+	if !j.decoder.More() {
+		return UpsertCityParams{}, io.EOF
+	}
+
+	var entry cityEntry
+	if err := j.decoder.Decode(&entry); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			// The decoder has read past the whole value, so the next one is fine
+			return UpsertCityParams{}, fmt.Errorf("%w: %s", errInvalidRecord, err)
+		}
+		return UpsertCityParams{}, err
+	}
+
+	return validCity(entry.ID, entry.Name, entry.Country, entry.State, entry.Coord.Lat, entry.Coord.Lon, entry.Population)
+}
+
+// GeoNames columns, see https://download.geonames.org/export/dump/readme.txt
+const (
+	geonamesID         = 0
+	geonamesName       = 1
+	geonamesLat        = 4
+	geonamesLon        = 5
+	geonamesCountry    = 8
+	geonamesPopulation = 14
+	geonamesColumns    = 19
+)
+
+type geonamesCityReader struct {
+	scanner *bufio.Scanner
+}
+
+func (g *geonamesCityReader) next() (UpsertCityParams, error) {
+	for g.scanner.Scan() {
+		line := g.scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < geonamesColumns {
+			return UpsertCityParams{}, fmt.Errorf("%w: expected %d columns, got %d", errInvalidRecord, geonamesColumns, len(fields))
+		}
+
+		id, err := strconv.ParseInt(fields[geonamesID], 10, 64)
+		if err != nil {
+			return UpsertCityParams{}, fmt.Errorf("%w: bad id %q", errInvalidRecord, fields[geonamesID])
+		}
+		lat, err := strconv.ParseFloat(fields[geonamesLat], 64)
+		if err != nil {
+			return UpsertCityParams{}, fmt.Errorf("%w: bad latitude %q", errInvalidRecord, fields[geonamesLat])
+		}
+		lon, err := strconv.ParseFloat(fields[geonamesLon], 64)
+		if err != nil {
+			return UpsertCityParams{}, fmt.Errorf("%w: bad longitude %q", errInvalidRecord, fields[geonamesLon])
+		}
+		// Population is optional
+		population, _ := strconv.ParseInt(fields[geonamesPopulation], 10, 64)
+
+		// The dump only has an admin1 code, not a state name. An empty state
+		// leaves the one already stored alone.
+		return validCity(id, fields[geonamesName], fields[geonamesCountry], "", lat, lon, population)
+	}
+
+	if err := g.scanner.Err(); err != nil {
+		return UpsertCityParams{}, err
+	}
+	return UpsertCityParams{}, io.EOF
+}
+
+func validCity(id int64, name, country, state string, lat, lon float64, population int64) (UpsertCityParams, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case id <= 0:
+		return UpsertCityParams{}, fmt.Errorf("%w: missing id", errInvalidRecord)
+	case name == "":
+		return UpsertCityParams{}, fmt.Errorf("%w: city %d has no name", errInvalidRecord, id)
+	case math.IsNaN(lat) || lat < -90 || lat > 90:
+		return UpsertCityParams{}, fmt.Errorf("%w: city %d has latitude %v", errInvalidRecord, id, lat)
+	case math.IsNaN(lon) || lon < -180 || lon > 180:
+		return UpsertCityParams{}, fmt.Errorf("%w: city %d has longitude %v", errInvalidRecord, id, lon)
+	}
+
+	return UpsertCityParams{
+		ID:      id,
+		Name:    name,
+		Country: strings.TrimSpace(country),
+		State:   strings.TrimSpace(state),
+		Lat:     lat,
+		Lon:     lon,
+		Population: sql.NullInt64{
+			Int64: population,
+			Valid: population > 0,
+		},
+		SearchName: NormalizeName(name),
+		Source:     CitySourceImport,
+	}, nil
+}
+
+// BackfillSearchNames fills in search_name for cities stored before the
+// column existed, batchSize rows per transaction. It returns how many it
+// filled in.
+func (s *CitySeeder) BackfillSearchNames(ctx context.Context, batchSize int) (int, error) {
+	filled := 0
+	for {
+		missing, err := s.queries.ListCitiesMissingSearchName(ctx, int64(batchSize))
+		if err != nil {
+			return filled, err
+		}
+		if len(missing) == 0 {
+			return filled, nil
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return filled, err
+		}
+		qtx := s.queries.WithTx(tx)
+
+		for _, city := range missing {
+			searchName := NormalizeName(city.Name)
+			if searchName == "" {
+				// Keeps a name of only marks or spaces from being picked up again
+				searchName = " "
+			}
+			err := qtx.SetCitySearchName(ctx, SetCitySearchNameParams{SearchName: searchName, ID: city.ID})
+			if err != nil {
+				tx.Rollback()
+				return filled, fmt.Errorf("failed to normalize city %s: %w", city.Name, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return filled, err
+		}
+		filled += len(missing)
+	}
+}
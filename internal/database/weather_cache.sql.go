@@ -10,6 +10,32 @@ import (
 	"database/sql"
 )
 
+const compactWeather = `-- name: CompactWeather :execrows
+DELETE FROM weather_cache
+WHERE fetched_at < ?1
+  AND id NOT IN (
+    SELECT MAX(id)
+    FROM weather_cache
+    WHERE fetched_at < ?1
+    GROUP BY ROUND(lat, 2), ROUND(lon, 2), lang, fetched_at / CAST(?2 AS INTEGER)
+  )
+`
+
+type CompactWeatherParams struct {
+	Before sql.NullInt64
+	Bucket int64
+}
+
+// Keeps only the newest row per spot, language and bucket of time among the
+// rows fetched before the cutoff.
+func (q *Queries) CompactWeather(ctx context.Context, arg CompactWeatherParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, compactWeather, arg.Before, arg.Bucket)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteDuplicateWeather = `-- name: DeleteDuplicateWeather :exec
 DELETE FROM weather_cache
 WHERE id NOT IN (
@@ -35,7 +61,7 @@ func (q *Queries) DeleteOldWeather(ctx context.Context, fetchedAt sql.NullInt64)
 }
 
 const getFreshWeatherByCity = `-- name: GetFreshWeatherByCity :one
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE city_name = ?
   AND fetched_at >= ?
@@ -81,20 +107,21 @@ func (q *Queries) GetFreshWeatherByCity(ctx context.Context, arg GetFreshWeather
 		&i.GroundLevel,
 		&i.Sunrise,
 		&i.Sunset,
+		&i.Provider,
+		&i.Lang,
 	)
 	return i, err
 }
 
-const getFreshWeatherByCoords = `-- name: GetFreshWeatherByCoords :one
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+const getFreshWeatherByCoords = `-- name: GetFreshWeatherByCoords :many
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE lat >= ?
   AND lat <= ?
-  AND lon >= ?
-  AND lon <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
   AND fetched_at >= ?
+  AND lang = ?
 ORDER BY fetched_at DESC
-LIMIT 1
 `
 
 type GetFreshWeatherByCoordsParams struct {
@@ -102,54 +129,78 @@ type GetFreshWeatherByCoordsParams struct {
 	Lat_2     sql.NullFloat64
 	Lon       sql.NullFloat64
 	Lon_2     sql.NullFloat64
+	Lon_3     sql.NullFloat64
+	Lon_4     sql.NullFloat64
 	FetchedAt sql.NullInt64
+	Lang      sql.NullString
 }
 
-func (q *Queries) GetFreshWeatherByCoords(ctx context.Context, arg GetFreshWeatherByCoordsParams) (WeatherCache, error) {
-	row := q.db.QueryRowContext(ctx, getFreshWeatherByCoords,
+func (q *Queries) GetFreshWeatherByCoords(ctx context.Context, arg GetFreshWeatherByCoordsParams) ([]WeatherCache, error) {
+	rows, err := q.db.QueryContext(ctx, getFreshWeatherByCoords,
 		arg.Lat,
 		arg.Lat_2,
 		arg.Lon,
 		arg.Lon_2,
+		arg.Lon_3,
+		arg.Lon_4,
 		arg.FetchedAt,
+		arg.Lang,
 	)
-	var i WeatherCache
-	err := row.Scan(
-		&i.ID,
-		&i.CityID,
-		&i.CityName,
-		&i.Country,
-		&i.Lat,
-		&i.Lon,
-		&i.WeatherMain,
-		&i.WeatherDesc,
-		&i.WeatherIcon,
-		&i.Temp,
-		&i.FeelsLike,
-		&i.TempMin,
-		&i.TempMax,
-		&i.Humidity,
-		&i.Pressure,
-		&i.WindSpeed,
-		&i.WindDeg,
-		&i.WindGust,
-		&i.Rain1h,
-		&i.Cloudiness,
-		&i.Visibility,
-		&i.WeatherTime,
-		&i.FetchedAt,
-		&i.Timezone,
-		&i.WeatherID,
-		&i.SeaLevel,
-		&i.GroundLevel,
-		&i.Sunrise,
-		&i.Sunset,
-	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WeatherCache
+	for rows.Next() {
+		var i WeatherCache
+		if err := rows.Scan(
+			&i.ID,
+			&i.CityID,
+			&i.CityName,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.WeatherMain,
+			&i.WeatherDesc,
+			&i.WeatherIcon,
+			&i.Temp,
+			&i.FeelsLike,
+			&i.TempMin,
+			&i.TempMax,
+			&i.Humidity,
+			&i.Pressure,
+			&i.WindSpeed,
+			&i.WindDeg,
+			&i.WindGust,
+			&i.Rain1h,
+			&i.Cloudiness,
+			&i.Visibility,
+			&i.WeatherTime,
+			&i.FetchedAt,
+			&i.Timezone,
+			&i.WeatherID,
+			&i.SeaLevel,
+			&i.GroundLevel,
+			&i.Sunrise,
+			&i.Sunset,
+			&i.Provider,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getLatestWeatherByCity = `-- name: GetLatestWeatherByCity :one
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE city_name = ?
 ORDER BY fetched_at DESC
@@ -189,12 +240,14 @@ func (q *Queries) GetLatestWeatherByCity(ctx context.Context, cityName sql.NullS
 		&i.GroundLevel,
 		&i.Sunrise,
 		&i.Sunset,
+		&i.Provider,
+		&i.Lang,
 	)
 	return i, err
 }
 
 const getLatestWeatherByCityID = `-- name: GetLatestWeatherByCityID :one
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE city_id = ?
 ORDER BY fetched_at DESC
@@ -234,20 +287,20 @@ func (q *Queries) GetLatestWeatherByCityID(ctx context.Context, cityID sql.NullI
 		&i.GroundLevel,
 		&i.Sunrise,
 		&i.Sunset,
+		&i.Provider,
+		&i.Lang,
 	)
 	return i, err
 }
 
-const getLatestWeatherByCoords = `-- name: GetLatestWeatherByCoords :one
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+const getLatestWeatherByCoords = `-- name: GetLatestWeatherByCoords :many
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE lat >= ?
   AND lat <= ?
-  AND lon >= ?
-  AND lon <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
   AND fetched_at >= ?
 ORDER BY fetched_at DESC
-LIMIT 1
 `
 
 type GetLatestWeatherByCoordsParams struct {
@@ -255,54 +308,76 @@ type GetLatestWeatherByCoordsParams struct {
 	Lat_2     sql.NullFloat64
 	Lon       sql.NullFloat64
 	Lon_2     sql.NullFloat64
+	Lon_3     sql.NullFloat64
+	Lon_4     sql.NullFloat64
 	FetchedAt sql.NullInt64
 }
 
-func (q *Queries) GetLatestWeatherByCoords(ctx context.Context, arg GetLatestWeatherByCoordsParams) (WeatherCache, error) {
-	row := q.db.QueryRowContext(ctx, getLatestWeatherByCoords,
+func (q *Queries) GetLatestWeatherByCoords(ctx context.Context, arg GetLatestWeatherByCoordsParams) ([]WeatherCache, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestWeatherByCoords,
 		arg.Lat,
 		arg.Lat_2,
 		arg.Lon,
 		arg.Lon_2,
+		arg.Lon_3,
+		arg.Lon_4,
 		arg.FetchedAt,
 	)
-	var i WeatherCache
-	err := row.Scan(
-		&i.ID,
-		&i.CityID,
-		&i.CityName,
-		&i.Country,
-		&i.Lat,
-		&i.Lon,
-		&i.WeatherMain,
-		&i.WeatherDesc,
-		&i.WeatherIcon,
-		&i.Temp,
-		&i.FeelsLike,
-		&i.TempMin,
-		&i.TempMax,
-		&i.Humidity,
-		&i.Pressure,
-		&i.WindSpeed,
-		&i.WindDeg,
-		&i.WindGust,
-		&i.Rain1h,
-		&i.Cloudiness,
-		&i.Visibility,
-		&i.WeatherTime,
-		&i.FetchedAt,
-		&i.Timezone,
-		&i.WeatherID,
-		&i.SeaLevel,
-		&i.GroundLevel,
-		&i.Sunrise,
-		&i.Sunset,
-	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WeatherCache
+	for rows.Next() {
+		var i WeatherCache
+		if err := rows.Scan(
+			&i.ID,
+			&i.CityID,
+			&i.CityName,
+			&i.Country,
+			&i.Lat,
+			&i.Lon,
+			&i.WeatherMain,
+			&i.WeatherDesc,
+			&i.WeatherIcon,
+			&i.Temp,
+			&i.FeelsLike,
+			&i.TempMin,
+			&i.TempMax,
+			&i.Humidity,
+			&i.Pressure,
+			&i.WindSpeed,
+			&i.WindDeg,
+			&i.WindGust,
+			&i.Rain1h,
+			&i.Cloudiness,
+			&i.Visibility,
+			&i.WeatherTime,
+			&i.FetchedAt,
+			&i.Timezone,
+			&i.WeatherID,
+			&i.SeaLevel,
+			&i.GroundLevel,
+			&i.Sunrise,
+			&i.Sunset,
+			&i.Provider,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getWeatherHistoryByCity = `-- name: GetWeatherHistoryByCity :many
-SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset
+SELECT id, city_id, city_name, country, lat, lon, weather_main, weather_desc, weather_icon, "temp", feels_like, temp_min, temp_max, humidity, pressure, wind_speed, wind_deg, wind_gust, rain_1h, cloudiness, visibility, weather_time, fetched_at, timezone, weather_id, sea_level, ground_level, sunrise, sunset, provider, lang
 FROM weather_cache
 WHERE city_name = ?
 ORDER BY fetched_at DESC
@@ -353,6 +428,8 @@ func (q *Queries) GetWeatherHistoryByCity(ctx context.Context, arg GetWeatherHis
 			&i.GroundLevel,
 			&i.Sunrise,
 			&i.Sunset,
+			&i.Provider,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -396,9 +473,11 @@ INSERT INTO weather_cache (
     sunset,
     weather_time,
     fetched_at,
-    timezone
+    timezone,
+    provider,
+    lang
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
@@ -431,6 +510,8 @@ type InsertWeatherParams struct {
 	WeatherTime sql.NullInt64
 	FetchedAt   sql.NullInt64
 	Timezone    sql.NullInt64
+	Provider    sql.NullString
+	Lang        sql.NullString
 }
 
 func (q *Queries) InsertWeather(ctx context.Context, arg InsertWeatherParams) error {
@@ -463,6 +544,8 @@ func (q *Queries) InsertWeather(ctx context.Context, arg InsertWeatherParams) er
 		arg.WeatherTime,
 		arg.FetchedAt,
 		arg.Timezone,
+		arg.Provider,
+		arg.Lang,
 	)
 	return err
 }
@@ -3,15 +3,16 @@ package ui
 import (
 	"fmt"
 	"github/Arnab-cloud/tui_weather_app/internal/weather"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/common-nighthawk/go-figure"
 )
 
-func renderWeather(weather *weather.WeatherResponse, width, height int) string {
+func renderWeather(weather *weather.WeatherResponse, forecast *weather.ForecastResponse, units weather.UnitSystem, msgs messages, width, height int) string {
 	// Hero section with location and temperature
-	fig := figure.NewColorFigure(fmt.Sprintf("%.1f", weather.Main.Temp), "slant", "yellow", true)
+	fig := figure.NewColorFigure(fmt.Sprintf("%.1f", units.Temp(weather.Main.Temp)), "slant", "yellow", true)
 	bigTemp := fig.String()
 
 	locationStyle := lipgloss.NewStyle().Foreground(white).Bold(true)
@@ -26,8 +27,21 @@ func renderWeather(weather *weather.WeatherResponse, width, height int) string {
 		location,
 		weatherDesc,
 		"",
-		formatHiLo(weather.Main.TempMax, weather.Main.TempMin),
+		formatHiLo(units, weather.Main.TempMax, weather.Main.TempMin),
 	)
+	if weather.Provider != "" {
+		heroLeft = lipgloss.JoinVertical(lipgloss.Left,
+			heroLeft,
+			providerStyle.Render(msgs.get(msgVia)+" "+weather.Provider),
+		)
+	}
+	if weather.Stale {
+		heroLeft = lipgloss.JoinVertical(lipgloss.Left,
+			heroLeft,
+			staleBadgeStyle.Render(fmt.Sprintf("%s · %s", msgs.get(msgOffline),
+				fmt.Sprintf(msgs.get(msgUpdatedAgo), formatAge(weather.Age())))),
+		)
+	}
 
 	hero := renderSection("",
 		lipgloss.JoinHorizontal(lipgloss.Center,
@@ -39,21 +53,27 @@ func renderWeather(weather *weather.WeatherResponse, width, height int) string {
 		yellow,
 	)
 
+	// Forecast, once it has arrived
+	var forecastSection string
+	if forecast != nil && len(forecast.List) > 0 {
+		forecastSection = renderForecast(forecast, units, msgs, width-10)
+	}
+
 	// Atmosphere grid
 	colWidth := (width / 3) - 6
 	atmRow1 := lipgloss.JoinHorizontal(lipgloss.Top,
-		renderDataPoint("🌡️ Feels Like", fmt.Sprintf("%.1f°C", weather.Main.FeelsLike), colWidth),
-		renderDataPoint("💧 Humidity", fmt.Sprintf("%d%%", weather.Main.Humidity), colWidth),
-		renderDataPoint("🌬️ Wind", fmt.Sprintf("%.1f m/s", weather.Wind.Speed), colWidth),
+		renderDataPoint("🌡️ "+msgs.get(msgFeelsLike), units.FormatTemp(weather.Main.FeelsLike), colWidth),
+		renderDataPoint("💧 "+msgs.get(msgHumidity), fmt.Sprintf("%d%%", weather.Main.Humidity), colWidth),
+		renderDataPoint("🌬️ "+msgs.get(msgWind), units.FormatSpeed(weather.Wind.Speed), colWidth),
 	)
 
 	atmRow2 := lipgloss.JoinHorizontal(lipgloss.Top,
-		renderDataPoint("⏲️ Pressure", fmt.Sprintf("%d hPa", weather.Main.Pressure), colWidth),
-		renderDataPoint("👁️ Visibility", fmt.Sprintf("%.1f km", float64(weather.Vis)/1000), colWidth),
-		renderDataPoint("☁️ Cloudiness", fmt.Sprintf("%d%%", weather.Clouds), colWidth),
+		renderDataPoint("⏲️ "+msgs.get(msgPressure), fmt.Sprintf("%d hPa", weather.Main.Pressure), colWidth),
+		renderDataPoint("👁️ "+msgs.get(msgVisibility), units.FormatDistance(float64(weather.Vis)), colWidth),
+		renderDataPoint("☁️ "+msgs.get(msgCloudiness), fmt.Sprintf("%d%%", weather.Clouds), colWidth),
 	)
 
-	atmosphere := renderSection("Atmosphere",
+	atmosphere := renderSection(msgs.get(msgAtmosphere),
 		lipgloss.JoinVertical(lipgloss.Left, atmRow1, atmRow2),
 		width-10,
 		cyan,
@@ -62,20 +82,20 @@ func renderWeather(weather *weather.WeatherResponse, width, height int) string {
 	// Sun times
 	halfWidth := (width / 2) - 8
 	sunContent := lipgloss.JoinHorizontal(lipgloss.Top,
-		renderDataPoint("🌅 Sunrise", time.Unix(weather.Sys.Sunrise, 0).Format("03:04 PM"), halfWidth/2),
-		renderDataPoint("🌇 Sunset", time.Unix(weather.Sys.Sunset, 0).Format("03:04 PM"), halfWidth/2),
+		renderDataPoint("🌅 "+msgs.get(msgSunrise), time.Unix(weather.Sys.Sunrise, 0).Format("03:04 PM"), halfWidth/2),
+		renderDataPoint("🌇 "+msgs.get(msgSunset), time.Unix(weather.Sys.Sunset, 0).Format("03:04 PM"), halfWidth/2),
 	)
 
-	sunSection := renderSection("Sun Times", sunContent, width-10, blue)
+	sunSection := renderSection(msgs.get(msgSunTimes), sunContent, width-10, blue)
 
 	// Assemble full view
-	fullView := lipgloss.JoinVertical(lipgloss.Left,
-		hero,
-		"",
-		atmosphere,
-		"",
-		sunSection,
-	)
+	sections := []string{hero, ""}
+	if forecastSection != "" {
+		sections = append(sections, forecastSection, "")
+	}
+	sections = append(sections, atmosphere, "", sunSection)
+
+	fullView := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	return windowStyle.
 		Width(width).
@@ -83,6 +103,288 @@ func renderWeather(weather *weather.WeatherResponse, width, height int) string {
 		Render(fullView)
 }
 
+// favoritesWidth is how wide the favorites pane is, border included.
+const favoritesWidth = 28
+
+func renderFavorites(favorites []weather.Favorite, current *weather.City, msgs messages) string {
+	lines := []string{titleStyle.Render("★ " + msgs.get(msgFavorites)), ""}
+	for i, fav := range favorites {
+		number := " "
+		if i < 9 {
+			number = fmt.Sprintf("%d", i+1)
+		}
+		name := fav.Name
+		if fav.Country != "" {
+			name += ", " + fav.Country
+		}
+
+		style, marker := favoriteStyle, " "
+		if current != nil && fav.Lat == current.Lat && fav.Lon == current.Lon {
+			style, marker = favoriteActiveStyle, "▸"
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s %s", marker, number, name)))
+	}
+
+	return favoritesPaneStyle.
+		Width(favoritesWidth - 1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// Size of a dashboard card, border included
+const (
+	cardWidth  = 30
+	cardHeight = 8
+)
+
+// dashboardColumns is how many cards fit next to each other in width.
+func dashboardColumns(width int) int {
+	return max(width/cardWidth, 1)
+}
+
+func renderDashboard(cards []dashboardCard, focus int, units weather.UnitSystem, msgs messages, width, height int) string {
+	title := titleStyle.Render("📊 " + msgs.get(msgDashboard))
+	if len(cards) == 0 {
+		return windowStyle.
+			Width(width).
+			Height(height).
+			Render(lipgloss.JoinVertical(lipgloss.Center, title, "", msgs.get(msgDashboardEmpty)))
+	}
+
+	// Only as many rows as fit, scrolled so the focused card is one of them
+	cols := dashboardColumns(width)
+	visible := max((height-2)/cardHeight, 1)
+	first := max(focus/cols-visible+1, 0)
+
+	var rows []string
+	for r := first; r < first+visible && r*cols < len(cards); r++ {
+		var row []string
+		for i := r * cols; i < min((r+1)*cols, len(cards)); i++ {
+			row = append(row, renderCard(cards[i], i, i == focus, units, msgs))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+
+	return windowStyle.
+		Width(width).
+		Height(height).
+		Render(lipgloss.JoinVertical(lipgloss.Center,
+			title,
+			"",
+			lipgloss.JoinVertical(lipgloss.Left, rows...),
+		))
+}
+
+func renderCard(card dashboardCard, i int, focused bool, units weather.UnitSystem, msgs messages) string {
+	name := card.favorite.Name
+	if card.favorite.Country != "" {
+		name += ", " + card.favorite.Country
+	}
+	if i < 9 {
+		name = fmt.Sprintf("%d %s", i+1, name)
+	}
+	if card.loading && card.weather != nil {
+		name += " ↻"
+	}
+	lines := []string{cardTitleStyle.Render(name)}
+
+	switch w := card.weather; {
+	case w != nil:
+		icon, desc := "", ""
+		if len(w.Weather) > 0 {
+			icon, desc = w.Weather[0].Icon, w.Weather[0].Desc
+		}
+		lines = append(lines,
+			getWeatherEmoji(icon)+"  "+cardTempStyle.Render(units.FormatTemp(w.Main.Temp)),
+			lipgloss.NewStyle().Foreground(fg).Render(desc),
+			formatHiLo(units, w.Main.TempMax, w.Main.TempMin),
+			"🌬️ "+units.FormatSpeed(w.Wind.Speed),
+		)
+		if w.Stale {
+			lines = append(lines, staleBadgeStyle.Render(fmt.Sprintf(msgs.get(msgUpdatedAgo), formatAge(w.Age()))))
+		}
+	case card.err != nil:
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(errorColor).Render("⚠️ "+msgs.get(msgFetchFailed)))
+	default:
+		lines = append(lines, "", msgs.get(msgLoading))
+	}
+
+	content := lipgloss.NewStyle().
+		MaxWidth(cardWidth - 4).
+		MaxHeight(cardHeight - 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+
+	if focused {
+		return cardFocusedStyle.Render(content)
+	}
+	return cardStyle.Render(content)
+}
+
+// compareRow is one line of the compare view. value gives what to show for
+// a location and the number to rank it by; ok is false when there is nothing
+// to show.
+type compareRow struct {
+	label string
+	value func(w *weather.WeatherResponse) (shown string, rank float64, ok bool)
+}
+
+func compareRows(units weather.UnitSystem, msgs messages) []compareRow {
+	// Sun times are shown and ranked by the clocks where the location is
+	clock := func(w *weather.WeatherResponse, t int64) (string, float64, bool) {
+		if t == 0 {
+			// The sun doesn't rise or set there today
+			return "", 0, false
+		}
+		local := w.LocalTime(t)
+		return local.Format("03:04 PM"), float64(local.Hour()*60 + local.Minute()), true
+	}
+
+	return []compareRow{
+		{"🌡️ " + msgs.get(msgTemperature), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return units.FormatTemp(w.Main.Temp), w.Main.Temp, true
+		}},
+		{"🌡️ " + msgs.get(msgFeelsLike), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return units.FormatTemp(w.Main.FeelsLike), w.Main.FeelsLike, true
+		}},
+		{"💧 " + msgs.get(msgHumidity), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return fmt.Sprintf("%d%%", w.Main.Humidity), float64(w.Main.Humidity), true
+		}},
+		{"🌬️ " + msgs.get(msgWind), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return units.FormatSpeed(w.Wind.Speed), w.Wind.Speed, true
+		}},
+		{"⏲️ " + msgs.get(msgPressure), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return fmt.Sprintf("%d hPa", w.Main.Pressure), float64(w.Main.Pressure), true
+		}},
+		{"🌅 " + msgs.get(msgSunrise), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return clock(w, w.Sys.Sunrise)
+		}},
+		{"🌇 " + msgs.get(msgSunset), func(w *weather.WeatherResponse) (string, float64, bool) {
+			return clock(w, w.Sys.Sunset)
+		}},
+	}
+}
+
+// renderCompare puts the locations in columns with the same rows, marking
+// the highest value of each row with ▲ and the lowest with ▼.
+func renderCompare(cities []weather.City, results []weather.LocationWeather, units weather.UnitSystem, msgs messages, width, height int) string {
+	title := titleStyle.Render("⇄ " + msgs.get(msgCompare))
+	if len(cities) < 2 {
+		return windowStyle.
+			Width(width).
+			Height(height).
+			Render(lipgloss.JoinVertical(lipgloss.Center, title, "", msgs.get(msgCompareHint)))
+	}
+
+	// Results for another set of locations are as good as none
+	if len(results) != len(cities) {
+		results = nil
+	}
+
+	colWidth := min(max((width-4)/len(cities), 16), 36)
+	cols := make([][]string, len(cities))
+	for i, city := range cities {
+		cols[i] = []string{cardTitleStyle.Width(colWidth).Padding(0, 1).Render(city.Place())}
+		switch {
+		case results == nil:
+			cols[i] = append(cols[i], lipgloss.NewStyle().Padding(1).Render(msgs.get(msgLoading)))
+		case results[i].Weather == nil:
+			cols[i] = append(cols[i], lipgloss.NewStyle().Foreground(errorColor).Padding(1).Render("⚠️ "+msgs.get(msgFetchFailed)))
+		}
+	}
+
+	for _, row := range compareRows(units, msgs) {
+		shown := make([]string, len(cities))
+		ranks := make([]float64, len(cities))
+		valid := make([]bool, len(cities))
+		hi, lo := -1, -1
+		for i := range cities {
+			if results == nil || results[i].Weather == nil {
+				continue
+			}
+			shown[i], ranks[i], valid[i] = row.value(results[i].Weather)
+			if !valid[i] {
+				continue
+			}
+			if hi < 0 || ranks[i] > ranks[hi] {
+				hi = i
+			}
+			if lo < 0 || ranks[i] < ranks[lo] {
+				lo = i
+			}
+		}
+		// Nothing stands out when they are all the same
+		if hi < 0 || ranks[hi] == ranks[lo] {
+			hi, lo = -1, -1
+		}
+
+		for i := range cities {
+			if results == nil || results[i].Weather == nil {
+				continue
+			}
+			value, style := "—", compareMissingStyle
+			switch {
+			case !valid[i]:
+			case i == hi:
+				value, style = "▲ "+shown[i], compareHighStyle
+			case i == lo:
+				value, style = "▼ "+shown[i], compareLowStyle
+			default:
+				value, style = shown[i], dataValueStyle
+			}
+			cols[i] = append(cols[i], renderStyledDataPoint(row.label, value, style, colWidth))
+		}
+	}
+
+	rendered := make([]string, len(cols))
+	for i, col := range cols {
+		rendered[i] = lipgloss.JoinVertical(lipgloss.Left, col...)
+	}
+	return windowStyle.
+		Width(width).
+		Height(height).
+		Render(lipgloss.JoinVertical(lipgloss.Center,
+			title,
+			"",
+			lipgloss.JoinHorizontal(lipgloss.Top, rendered...),
+		))
+}
+
+// renderCompareBar lists the locations marked for comparing.
+func renderCompareBar(cities []weather.City, msgs messages) string {
+	names := make([]string, len(cities))
+	for i, city := range cities {
+		names[i] = city.Name
+	}
+	return compareBarStyle.Render(fmt.Sprintf("⇄ %s: %s", msgs.get(msgCompare), strings.Join(names, " · ")))
+}
+
+func renderForecast(forecast *weather.ForecastResponse, units weather.UnitSystem, msgs messages, width int) string {
+	days := forecast.Daily()
+	if len(days) == 0 {
+		return ""
+	}
+
+	colWidth := max(width/len(days)-2, 10)
+	dayStyle := lipgloss.NewStyle().Foreground(magenta).Bold(true)
+	popStyle := lipgloss.NewStyle().Foreground(blue)
+
+	cols := make([]string, 0, len(days))
+	for _, day := range days {
+		col := lipgloss.JoinVertical(lipgloss.Center,
+			dayStyle.Render(day.Date.Format("Mon 02")),
+			getWeatherEmoji(day.Icon),
+			formatHiLo(units, day.TempMax, day.TempMin),
+			popStyle.Render(fmt.Sprintf("☔ %.0f%%", day.Pop*100)),
+		)
+		cols = append(cols, lipgloss.NewStyle().
+			Width(colWidth).
+			Padding(0, 1).
+			Align(lipgloss.Center).
+			Render(col))
+	}
+
+	return renderSection(msgs.get(msgForecast), lipgloss.JoinHorizontal(lipgloss.Top, cols...), width, green)
+}
+
 func renderSection(title, content string, width int, color lipgloss.Color) string {
 	sectionTitle := lipgloss.NewStyle().
 		Foreground(color).
@@ -109,8 +411,12 @@ func renderSection(title, content string, width int, color lipgloss.Color) strin
 }
 
 func renderDataPoint(label, value string, width int) string {
+	return renderStyledDataPoint(label, value, dataValueStyle, width)
+}
+
+func renderStyledDataPoint(label, value string, valueStyle lipgloss.Style, width int) string {
 	l := lipgloss.NewStyle().Foreground(magenta).Render(label)
-	v := lipgloss.NewStyle().Foreground(green).Bold(true).Render(value)
+	v := valueStyle.Render(value)
 
 	return lipgloss.NewStyle().
 		Width(width).
@@ -118,16 +424,28 @@ func renderDataPoint(label, value string, width int) string {
 		Render(lipgloss.JoinVertical(lipgloss.Left, l, v))
 }
 
-func formatHiLo(hi, lo float64) string {
+func formatHiLo(units weather.UnitSystem, hi, lo float64) string {
 	high := lipgloss.JoinHorizontal(lipgloss.Left,
 		hiLoLabelStyle.Render("H:"),
-		hiLoValueStyle.Render(fmt.Sprintf("%.0f°", hi)),
+		hiLoValueStyle.Render(units.FormatTempShort(hi)),
 	)
 
 	low := lipgloss.JoinHorizontal(lipgloss.Left,
 		hiLoLabelStyle.Render("L:"),
-		hiLoValueStyle.Render(fmt.Sprintf("%.0f°", lo)),
+		hiLoValueStyle.Render(units.FormatTempShort(lo)),
 	)
 
 	return lipgloss.JoinHorizontal(lipgloss.Left, high, "  ", low)
 }
+
+// formatAge rounds d to its largest whole unit: "45m", "2h", "3d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", max(int(d.Minutes()), 1))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
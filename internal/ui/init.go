@@ -1,7 +1,25 @@
 package ui
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
 
+// Init starts listening for background refreshes, loads the favorites and,
+// for a restored session, fetches the weather it left off at, including the
+// locations being compared.
 func (curM StateModel) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{
+		waitForRefresh(curM.service),
+		loadFavorites(curM.service, true),
+		curM.performWeatherSearch(),
+		curM.performForecastSearch(),
+	}
+	if curM.isCompareOpen {
+		cmds = append(cmds, curM.fetchCompared())
+	}
+	if curM.textInput.Focused() {
+		cmds = append(cmds, textinput.Blink, curM.performLocationSearch())
+	}
+	return tea.Batch(cmds...)
 }
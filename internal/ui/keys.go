@@ -10,8 +10,23 @@ type itemsKeyMap struct {
 	up           key.Binding // "k"
 	down         key.Binding // "j"
 	back         key.Binding // "esc"
+	units        key.Binding // "u"
 	quit         key.Binding // "q"
 	help         key.Binding
+
+	addFavorite      key.Binding // "a"
+	removeFavorite   key.Binding // "x"
+	moveFavoriteUp   key.Binding // "K"
+	moveFavoriteDown key.Binding // "J"
+	jumpFavorite     key.Binding // "1" to "9"
+	toggleFavorites  key.Binding // "f"
+
+	dashboard key.Binding // "d"
+	left      key.Binding // "h"
+	right     key.Binding // "l"
+
+	markCompare key.Binding // "c"
+	compare     key.Binding // "C"
 }
 
 func newItemsKeyMap() *itemsKeyMap {
@@ -40,27 +55,93 @@ func newItemsKeyMap() *itemsKeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back"),
 		),
+		units: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "units"),
+		),
 		quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		addFavorite: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "save"),
+		),
+		removeFavorite: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "unsave"),
+		),
+		moveFavoriteUp: key.NewBinding(
+			key.WithKeys("K"),
+			key.WithHelp("K/J", "reorder"),
+		),
+		moveFavoriteDown: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "move down"),
+		),
+		jumpFavorite: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "favorite"),
+		),
+		toggleFavorites: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "favorites"),
+		),
+		dashboard: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "dashboard"),
+		),
+		left: key.NewBinding(
+			key.WithKeys("h", "left"),
+			key.WithHelp("h/←", "left"),
+		),
+		right: key.NewBinding(
+			key.WithKeys("l", "right"),
+			key.WithHelp("l/→", "right"),
+		),
+		markCompare: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "mark"),
+		),
+		compare: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "compare"),
+		),
 	}
 }
 
 func (k itemsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.toggleFilter, k.quit}
+	return []key.Binding{k.toggleFilter, k.units, k.quit}
 }
 
 func (k itemsKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.up, k.down, k.choose},
-		{k.toggleFilter, k.back, k.quit},
+		{k.toggleFilter, k.units, k.back, k.quit},
+		{k.addFavorite, k.removeFavorite, k.moveFavoriteUp, k.moveFavoriteDown, k.jumpFavorite, k.toggleFavorites},
+		{k.dashboard, k.left, k.right, k.markCompare, k.compare},
 	}
 }
 
-func (k itemsKeyMap) GetContextualHelp(isFilterOpen, isInputFocused bool) []key.Binding {
+func (k itemsKeyMap) GetContextualHelp(isFilterOpen, isInputFocused, isDashboardOpen, isCompareOpen bool) []key.Binding {
+	if !isFilterOpen && isCompareOpen {
+		return []key.Binding{k.compare, k.back, k.units, k.quit}
+	}
+
+	if !isFilterOpen && isDashboardOpen {
+		move := key.NewBinding(
+			key.WithKeys("up", "down", "left", "right"),
+			key.WithHelp("←↓↑→", "move"),
+		)
+		open := key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "details"),
+		)
+		return []key.Binding{move, open, k.dashboard, k.removeFavorite, k.moveFavoriteUp, k.markCompare, k.compare, k.units, k.quit}
+	}
+
 	if !isFilterOpen {
-		return []key.Binding{k.toggleFilter, k.quit}
+		return []key.Binding{k.toggleFilter, k.dashboard, k.jumpFavorite, k.addFavorite, k.removeFavorite, k.moveFavoriteUp, k.toggleFavorites, k.markCompare, k.compare, k.units, k.quit}
 	}
 
 	if isInputFocused {
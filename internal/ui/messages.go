@@ -0,0 +1,141 @@
+package ui
+
+type msgKey int
+
+const (
+	msgFindCities msgKey = iota
+	msgWeatherSearch
+	msgSearchPlaceholder
+	msgLoading
+	msgFetchFailed
+	msgInvalidKey
+	msgError
+	msgVia
+	msgForecast
+	msgAtmosphere
+	msgSunTimes
+	msgFeelsLike
+	msgHumidity
+	msgWind
+	msgPressure
+	msgVisibility
+	msgCloudiness
+	msgSunrise
+	msgSunset
+	msgOffline
+	msgUpdatedAgo
+	msgFavorites
+	msgDashboard
+	msgDashboardEmpty
+	msgCompare
+	msgCompareHint
+	msgTemperature
+)
+
+// catalog holds the UI strings per language. English is the fallback for
+// languages or keys that are missing.
+var catalog = map[string]map[msgKey]string{
+	"en": {
+		msgFindCities:        "Find Cities",
+		msgWeatherSearch:     "Weather Search",
+		msgSearchPlaceholder: "Search for a city or lat, lon",
+		msgLoading:           "Loading...",
+		msgFetchFailed:       "Could not fetch the weather",
+		msgInvalidKey:        "The weather API rejected your key. Check API_KEY in your .env file",
+		msgError:             "Error",
+		msgVia:               "via",
+		msgForecast:          "Forecast",
+		msgAtmosphere:        "Atmosphere",
+		msgSunTimes:          "Sun Times",
+		msgFeelsLike:         "Feels Like",
+		msgHumidity:          "Humidity",
+		msgWind:              "Wind",
+		msgPressure:          "Pressure",
+		msgVisibility:        "Visibility",
+		msgCloudiness:        "Cloudiness",
+		msgSunrise:           "Sunrise",
+		msgSunset:            "Sunset",
+		msgOffline:           "offline",
+		msgUpdatedAgo:        "updated %s ago",
+		msgFavorites:         "Favorites",
+		msgDashboard:         "Dashboard",
+		msgDashboardEmpty:    "No favorites yet. Press a on a location to add it.",
+		msgCompare:           "Compare",
+		msgCompareHint:       "Mark two or three locations with c, then press C to compare them.",
+		msgTemperature:       "Temperature",
+	},
+	"es": {
+		msgFindCities:        "Buscar ciudades",
+		msgWeatherSearch:     "Buscar el tiempo",
+		msgSearchPlaceholder: "Busca una ciudad o lat, lon",
+		msgLoading:           "Cargando...",
+		msgFetchFailed:       "No se pudo obtener el tiempo",
+		msgInvalidKey:        "La API del tiempo rechazó tu clave. Revisa API_KEY en tu archivo .env",
+		msgError:             "Error",
+		msgVia:               "vía",
+		msgForecast:          "Pronóstico",
+		msgAtmosphere:        "Atmósfera",
+		msgSunTimes:          "Horas de sol",
+		msgFeelsLike:         "Sensación",
+		msgHumidity:          "Humedad",
+		msgWind:              "Viento",
+		msgPressure:          "Presión",
+		msgVisibility:        "Visibilidad",
+		msgCloudiness:        "Nubosidad",
+		msgSunrise:           "Amanecer",
+		msgSunset:            "Atardecer",
+		msgOffline:           "sin conexión",
+		msgUpdatedAgo:        "actualizado hace %s",
+		msgFavorites:         "Favoritos",
+		msgDashboard:         "Panel",
+		msgDashboardEmpty:    "Aún no hay favoritos. Pulsa a en una ubicación para añadirla.",
+		msgCompare:           "Comparar",
+		msgCompareHint:       "Marca dos o tres ubicaciones con c y pulsa C para compararlas.",
+		msgTemperature:       "Temperatura",
+	},
+	"de": {
+		msgFindCities:        "Städte suchen",
+		msgWeatherSearch:     "Wettersuche",
+		msgSearchPlaceholder: "Nach einer Stadt oder Breite, Länge suchen",
+		msgLoading:           "Wird geladen...",
+		msgFetchFailed:       "Das Wetter konnte nicht abgerufen werden",
+		msgInvalidKey:        "Die Wetter-API hat deinen Schlüssel abgelehnt. Prüfe API_KEY in deiner .env-Datei",
+		msgError:             "Fehler",
+		msgVia:               "über",
+		msgForecast:          "Vorhersage",
+		msgAtmosphere:        "Atmosphäre",
+		msgSunTimes:          "Sonnenzeiten",
+		msgFeelsLike:         "Gefühlt",
+		msgHumidity:          "Luftfeuchtigkeit",
+		msgWind:              "Wind",
+		msgPressure:          "Luftdruck",
+		msgVisibility:        "Sichtweite",
+		msgCloudiness:        "Bewölkung",
+		msgSunrise:           "Sonnenaufgang",
+		msgSunset:            "Sonnenuntergang",
+		msgOffline:           "offline",
+		msgUpdatedAgo:        "vor %s aktualisiert",
+		msgFavorites:         "Favoriten",
+		msgDashboard:         "Übersicht",
+		msgDashboardEmpty:    "Noch keine Favoriten. Drücke a bei einem Ort, um ihn hinzuzufügen.",
+		msgCompare:           "Vergleich",
+		msgCompareHint:       "Markiere zwei oder drei Orte mit c und drücke C, um sie zu vergleichen.",
+		msgTemperature:       "Temperatur",
+	},
+}
+
+type messages map[msgKey]string
+
+func messagesFor(lang string) messages {
+	if m, ok := catalog[lang]; ok {
+		return m
+	}
+	return catalog["en"]
+}
+
+func (m messages) get(key msgKey) string {
+	if s, ok := m[key]; ok {
+		return s
+	}
+	return catalog["en"][key]
+}
@@ -13,13 +13,30 @@ type StateModel struct {
 
 	textInput         textinput.Model
 	searchResults     list.Model
+	searchQuery       string
+	hasMoreCities     bool
+	isLoadingMore     bool
 	curItem           *weather.City
 	curWeather        *weather.WeatherResponse
+	curForecast       *weather.ForecastResponse
+	favorites         []weather.Favorite
+	showFavorites     bool
+	isDashboardOpen   bool
+	cards             []dashboardCard
+	cardFocus         int
+	dashboardGen      int
+	compareSet        []weather.City
+	compared          []weather.LocationWeather
+	isCompareOpen     bool
+	compareGen        int
+	units             weather.UnitSystem
+	msgs              messages
 	isFilterOpen      bool
 	isFetchingWeather bool
 	keys              *itemsKeyMap
 	help              help.Model
 	err               error
+	fetchErr          error
 	debounceId        int
 	width             int
 	height            int
@@ -27,12 +44,73 @@ type StateModel struct {
 	logging bool
 }
 
+// citySearchResultMsg carries one page of the cities found for query,
+// starting at offset. more is set when the page was full, so there may be
+// another one.
 type citySearchResultMsg struct {
-	locs []list.Item
+	locs   []list.Item
+	query  string
+	offset int
+	more   bool
 }
 
+// weatherSearchResultMsg carries the weather for coord, which may no longer
+// be the location on screen by the time it arrives.
 type weatherSearchResultMsg struct {
 	weather *weather.WeatherResponse
+	err     error
+	// refresh is set when the result comes from a background refresh
+	refresh bool
+	coord   weather.Coordinates
+}
+
+// favoritesMsg carries the favorites after they were loaded or changed.
+// initial is set for the load at startup.
+type favoritesMsg struct {
+	favorites []weather.Favorite
+	err       error
+	initial   bool
+}
+
+// dashboardCard is one favorite on the dashboard. It keeps showing the last
+// weather it had while a refresh is loading.
+type dashboardCard struct {
+	favorite weather.Favorite
+	weather  *weather.WeatherResponse
+	err      error
+	loading  bool
+}
+
+// dashboardMsg carries one card's weather. gen tells apart the results of
+// an older round of fetches, and results is where the rest will come from.
+type dashboardMsg struct {
+	gen     int
+	result  weather.LocationWeather
+	results <-chan weather.LocationWeather
+	done    bool
+}
+
+type dashboardTickMsg struct {
+	gen int
+}
+
+// compareMsg carries the weather for every location being compared, in the
+// order of compareSet.
+type compareMsg struct {
+	gen     int
+	results []weather.LocationWeather
+}
+
+// locationNamedMsg carries the name found for a point typed into the search.
+type locationNamedMsg struct {
+	city weather.City
+}
+
+// forecastSearchResultMsg carries the forecast for coord, which may no longer
+// be the location on screen by the time it arrives.
+type forecastSearchResultMsg struct {
+	forecast *weather.ForecastResponse
+	coord    weather.Coordinates
 }
 
 type debouncedMsg struct {
@@ -46,10 +124,11 @@ type errorMsg struct {
 
 func (e errorMsg) Error() string { return e.err.Error() }
 
-func NewModel(service *weather.WeatherService) StateModel {
+func NewModel(service *weather.WeatherService, units weather.UnitSystem, lang string) StateModel {
+	msgs := messagesFor(lang)
 	newSearchResults := list.New(nil, list.NewDefaultDelegate(), 0, 0)
 	ti := textinput.New()
-	ti.Placeholder = "Search for a city"
+	ti.Placeholder = msgs.get(msgSearchPlaceholder)
 	ti.CharLimit = 50
 	ti.Width = 40
 
@@ -59,14 +138,18 @@ func NewModel(service *weather.WeatherService) StateModel {
 		searchResults:     newSearchResults,
 		curItem:           nil,
 		curWeather:        nil,
+		curForecast:       nil,
+		units:             units,
+		msgs:              msgs,
 		isFilterOpen:      false,
+		showFavorites:     true,
 		isFetchingWeather: false,
 		debounceId:        0,
 		err:               nil,
 		keys:              newItemsKeyMap(),
 		help:              help.New(),
 	}
-	newModel.searchResults.Title = "Find Cities"
+	newModel.searchResults.Title = msgs.get(msgFindCities)
 	newModel.searchResults.SetShowFilter(false)
 	newModel.searchResults.SetShowHelp(false)
 	newModel.searchResults.SetFilteringEnabled(false)
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"github/Arnab-cloud/tui_weather_app/internal/weather"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Views a session can be left in
+const (
+	viewWeather   = "weather"
+	viewSearch    = "search"
+	viewDashboard = "dashboard"
+	viewCompare   = "compare"
+)
+
+// Session is what the app remembers between runs.
+type Session struct {
+	Location      *weather.City  `json:"location,omitempty"`
+	Units         string         `json:"units,omitempty"`
+	View          string         `json:"view,omitempty"`
+	HideFavorites bool           `json:"hide_favorites,omitempty"`
+	Compare       []weather.City `json:"compare,omitempty"`
+}
+
+// LoadSession reads the session saved at path. There being none yet is not
+// an error.
+func LoadSession(path string) (Session, error) {
+	var session Session
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	err = json.Unmarshal(data, &session)
+	return session, err
+}
+
+// Save writes the session to path, replacing the old one in one step so a
+// crash can't leave half a file behind.
+func (s Session) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Session is the state of the model worth restoring next time.
+func (curM StateModel) Session() Session {
+	view := viewWeather
+	switch {
+	case curM.isFilterOpen:
+		view = viewSearch
+	case curM.isCompareOpen:
+		view = viewCompare
+	case curM.isDashboardOpen:
+		view = viewDashboard
+	}
+	return Session{
+		Location:      curM.curItem,
+		Units:         curM.units.String(),
+		View:          view,
+		HideFavorites: !curM.showFavorites,
+		Compare:       curM.compareSet,
+	}
+}
+
+// Restore picks up where session left off. Init then fetches the weather for
+// the restored location.
+func (curM StateModel) Restore(session Session) StateModel {
+	if session.Units != "" {
+		units, err := weather.ParseUnitSystem(session.Units)
+		if err != nil {
+			log.Printf("ignoring the saved units: %s", err)
+		} else {
+			curM.units = units
+		}
+	}
+
+	if session.Location != nil {
+		location := *session.Location
+		curM.curItem = &location
+		curM.isFetchingWeather = true
+	}
+
+	switch session.View {
+	case viewSearch:
+		curM.isFilterOpen = true
+		curM.textInput.Focus()
+	case viewDashboard:
+		// Filled in once the favorites are loaded
+		curM.isDashboardOpen = true
+	case viewCompare:
+		curM.isCompareOpen = true
+	}
+	curM.compareSet = session.Compare
+	curM.showFavorites = !session.HideFavorites
+	return curM
+}
@@ -36,6 +36,65 @@ var (
 			Foreground(fg).
 			Bold(true)
 
+	providerStyle = lipgloss.NewStyle().
+			Foreground(comment).
+			Italic(true)
+
+	staleBadgeStyle = lipgloss.NewStyle().
+			Foreground(yellow).
+			Bold(true)
+
+	favoritesPaneStyle = lipgloss.NewStyle().
+				Padding(1, 1).
+				Border(lipgloss.RoundedBorder(), false, true, false, false).
+				BorderForeground(borderColor)
+
+	favoriteStyle = lipgloss.NewStyle().
+			Foreground(fg).
+			MaxWidth(favoritesWidth - 3)
+
+	favoriteActiveStyle = lipgloss.NewStyle().
+				Foreground(cyan).
+				Bold(true).
+				MaxWidth(favoritesWidth - 3)
+
+	cardStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(borderColor).
+			Padding(0, 1).
+			Width(cardWidth - 2).
+			Height(cardHeight - 2)
+
+	cardFocusedStyle = cardStyle.
+				BorderForeground(cyan)
+
+	cardTitleStyle = lipgloss.NewStyle().
+			Foreground(white).
+			Bold(true)
+
+	cardTempStyle = lipgloss.NewStyle().
+			Foreground(yellow).
+			Bold(true)
+
+	dataValueStyle = lipgloss.NewStyle().
+			Foreground(green).
+			Bold(true)
+
+	compareHighStyle = lipgloss.NewStyle().
+				Foreground(yellow).
+				Bold(true)
+
+	compareLowStyle = lipgloss.NewStyle().
+			Foreground(cyan).
+			Bold(true)
+
+	compareMissingStyle = lipgloss.NewStyle().
+				Foreground(comment)
+
+	compareBarStyle = lipgloss.NewStyle().
+			Foreground(comment).
+			Padding(0, 1)
+
 	errorStyle = lipgloss.NewStyle().
 			Foreground(errorColor).
 			Bold(true).
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github/Arnab-cloud/tui_weather_app/internal/weather"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -11,7 +12,19 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-var debounceDuration = 500 * time.Millisecond
+// Searches are answered from memory, so this only coalesces fast typing
+var debounceDuration = 50 * time.Millisecond
+
+// reverseGeocodeTimeout is how long a point typed into the search may wait
+// for a name before its weather is fetched without one.
+var reverseGeocodeTimeout = 5 * time.Second
+
+// dashboardRefresh is how often the dashboard refetches its cards. The cache
+// answers until its readings are CacheDuration old.
+var dashboardRefresh = weather.CacheDuration
+
+// maxCompared is how many locations fit side by side in the compare view.
+const maxCompared = 3
 
 func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd = nil
@@ -22,12 +35,89 @@ func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		curM.searchResults.SetSize(msg.Width-4, msg.Height-8)
 
 	case citySearchResultMsg:
-		curM.searchResults.SetItems(msg.locs)
+		if msg.offset == 0 {
+			curM.searchQuery = msg.query
+			curM.searchResults.SetItems(msg.locs)
+		} else if msg.query == curM.searchQuery && msg.offset == len(curM.searchResults.Items()) {
+			curM.searchResults.SetItems(append(slices.Clone(curM.searchResults.Items()), msg.locs...))
+		} else {
+			// A page for results that were replaced since
+			return curM, nil
+		}
+		curM.hasMoreCities = msg.more
+		curM.isLoadingMore = false
 
 	case weatherSearchResultMsg:
+		if msg.refresh {
+			cmd = waitForRefresh(curM.service)
+			curM.refreshCards(msg.coord, msg.weather)
+			curM.refreshCompared(msg.coord, msg.weather)
+		}
+		if !curM.isShowing(msg.coord) {
+			return curM, cmd
+		}
 		curM.curWeather = msg.weather
+		curM.fetchErr = msg.err
 		curM.isFetchingWeather = false
 
+	case locationNamedMsg:
+		if !curM.isShowing(weather.Coordinates{Lat: msg.city.Lat, Lon: msg.city.Lon}) {
+			return curM, nil
+		}
+		curM.service.RecordSelection(msg.city)
+		return curM, curM.showCity(msg.city)
+
+	case forecastSearchResultMsg:
+		if !curM.isShowing(msg.coord) {
+			return curM, nil
+		}
+		curM.curForecast = msg.forecast
+
+	case favoritesMsg:
+		if msg.err != nil {
+			log.Printf("error updating the favorites: %s", msg.err)
+		} else {
+			curM.favorites = msg.favorites
+		}
+		if curM.isDashboardOpen {
+			return curM, curM.startDashboard()
+		}
+		if msg.initial && curM.curItem == nil {
+			if len(curM.favorites) > 0 {
+				return curM, curM.showCity(curM.favorites[0].City)
+			}
+			// Nothing saved yet, so there is nothing to show but the search
+			curM.isFilterOpen = true
+			return curM, tea.Batch(curM.textInput.Focus(), curM.performLocationSearch())
+		}
+
+	case dashboardMsg:
+		if msg.gen != curM.dashboardGen || msg.done {
+			return curM, nil
+		}
+		if i := msg.result.Index; i < len(curM.cards) {
+			curM.cards = slices.Clone(curM.cards)
+			card := &curM.cards[i]
+			card.loading = false
+			card.err = msg.result.Err
+			if msg.result.Weather != nil {
+				card.weather = msg.result.Weather
+			}
+		}
+		return curM, waitForCard(msg.gen, msg.results)
+
+	case compareMsg:
+		if msg.gen == curM.compareGen {
+			curM.compared = msg.results
+		}
+		return curM, nil
+
+	case dashboardTickMsg:
+		if msg.gen != curM.dashboardGen || !curM.isDashboardOpen {
+			return curM, nil
+		}
+		return curM, curM.startDashboard()
+
 	case debouncedMsg:
 		if curM.debounceId != msg.id {
 			return curM, nil
@@ -45,10 +135,52 @@ func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, curM.keys.quit) && !curM.textInput.Focused():
 			return curM, tea.Quit
 
+		case key.Matches(msg, curM.keys.units) && !curM.textInput.Focused():
+			// Everything is kept in canonical units, so switching is only a re-render
+			curM.units = curM.units.Next()
+			return curM, nil
+
+		case curM.isCompareOpen && !curM.isFilterOpen && key.Matches(msg, curM.keys.back, curM.keys.compare):
+			curM.isCompareOpen = false
+			return curM, nil
+
+		case curM.isCompareOpen && !curM.isFilterOpen:
+			// Nothing else to do here but look
+			return curM, nil
+
+		case key.Matches(msg, curM.keys.compare) && !curM.isFilterOpen:
+			curM.isCompareOpen = true
+			return curM, curM.startCompare()
+
+		case key.Matches(msg, curM.keys.markCompare) && !curM.isFilterOpen:
+			curM.toggleCompared()
+			return curM, nil
+
+		case curM.isDashboardOpen && !curM.isFilterOpen && key.Matches(msg, curM.keys.up, curM.keys.down, curM.keys.left, curM.keys.right):
+			curM.moveCardFocus(msg)
+			return curM, nil
+
+		case curM.isDashboardOpen && !curM.isFilterOpen && key.Matches(msg, curM.keys.choose):
+			if curM.cardFocus < len(curM.cards) {
+				cmd = curM.showCity(curM.cards[curM.cardFocus].favorite.City)
+			}
+			return curM, cmd
+
+		case curM.isDashboardOpen && !curM.isFilterOpen && key.Matches(msg, curM.keys.back):
+			curM.isDashboardOpen = false
+			return curM, nil
+
+		case key.Matches(msg, curM.keys.dashboard) && !curM.isFilterOpen:
+			curM.isDashboardOpen = !curM.isDashboardOpen
+			if curM.isDashboardOpen {
+				return curM, curM.startDashboard()
+			}
+			return curM, nil
+
 		case key.Matches(msg, curM.keys.toggleFilter):
 			if !curM.isFilterOpen {
 				curM.isFilterOpen = true
-				return curM, curM.textInput.Focus()
+				return curM, tea.Batch(curM.textInput.Focus(), curM.performLocationSearch())
 			}
 
 			if !curM.textInput.Focused() {
@@ -58,13 +190,56 @@ func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			curM.textInput.Blur()
 			return curM, nil
 
+		case key.Matches(msg, curM.keys.jumpFavorite) && !curM.isFilterOpen:
+			if n := int(msg.String()[0] - '1'); n < len(curM.favorites) {
+				cmd = curM.showCity(curM.favorites[n].City)
+			}
+			return curM, cmd
+
+		case key.Matches(msg, curM.keys.addFavorite) && !curM.isFilterOpen && curM.curItem != nil:
+			city := *curM.curItem
+			return curM, changeFavorites(curM.service, func(ctx context.Context) error {
+				return curM.service.AddFavorite(ctx, city)
+			})
+
+		case key.Matches(msg, curM.keys.removeFavorite) && !curM.isFilterOpen:
+			if fav, ok := curM.currentFavorite(); ok {
+				cmd = changeFavorites(curM.service, func(ctx context.Context) error {
+					return curM.service.RemoveFavorite(ctx, fav.ID)
+				})
+			}
+			return curM, cmd
+
+		case key.Matches(msg, curM.keys.moveFavoriteUp, curM.keys.moveFavoriteDown) && !curM.isFilterOpen:
+			by := 1
+			if key.Matches(msg, curM.keys.moveFavoriteUp) {
+				by = -1
+			}
+			if fav, ok := curM.currentFavorite(); ok {
+				cmd = changeFavorites(curM.service, func(ctx context.Context) error {
+					return curM.service.MoveFavorite(ctx, fav.ID, by)
+				})
+				// The focus moves along with the card
+				curM.cardFocus = min(max(curM.cardFocus+by, 0), max(len(curM.cards)-1, 0))
+			}
+			return curM, cmd
+
+		case key.Matches(msg, curM.keys.toggleFavorites) && !curM.isFilterOpen:
+			curM.showFavorites = !curM.showFavorites
+			return curM, nil
+
 		case key.Matches(msg, curM.keys.choose):
 			if i, ok := curM.searchResults.SelectedItem().(weather.City); ok {
-				curM.curItem = &i
-				curM.isFilterOpen = false
-				curM.textInput.Blur()
-				curM.isFetchingWeather = true
-				cmd = curM.performWeatherSearch()
+				if coord, ok := weather.ParseCoordinates(curM.textInput.Value()); ok && i.Lat == coord.Lat && i.Lon == coord.Lon {
+					// A point typed in: its weather is fetched once it has a name
+					curM.showCity(i)
+					return curM, nameLocation(curM.service, coord)
+				}
+				curM.service.RecordSelection(i)
+				cmd = curM.showCity(i)
+			} else if curM.isFilterOpen {
+				// Nothing known by that name here, so ask the provider
+				cmd = curM.submitLocationSearch()
 			}
 			return curM, cmd
 		case key.Matches(msg, curM.keys.back):
@@ -83,7 +258,7 @@ func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		default:
 			if !curM.textInput.Focused() {
 				curM.searchResults, cmd = curM.searchResults.Update(msg)
-				return curM, cmd
+				return curM, tea.Batch(cmd, curM.loadMoreCities())
 			}
 
 			curM.textInput, cmd = curM.textInput.Update(msg)
@@ -96,6 +271,218 @@ func (curM StateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 }
 
+// showCity switches to the weather at city.
+func (curM *StateModel) showCity(city weather.City) tea.Cmd {
+	curM.curItem = &city
+	curM.isFilterOpen = false
+	curM.isDashboardOpen = false
+	curM.isCompareOpen = false
+	curM.textInput.Blur()
+	curM.isFetchingWeather = true
+	curM.curForecast = nil
+	return tea.Batch(curM.performWeatherSearch(), curM.performForecastSearch())
+}
+
+// isShowing reports whether coord is the location on screen.
+func (curM StateModel) isShowing(coord weather.Coordinates) bool {
+	return curM.curItem != nil && curM.curItem.Lat == coord.Lat && curM.curItem.Lon == coord.Lon
+}
+
+// currentFavorite is the favorite on screen, if the location on screen is one,
+// or the focused card on the dashboard.
+func (curM StateModel) currentFavorite() (weather.Favorite, bool) {
+	if curM.isDashboardOpen {
+		if curM.cardFocus < len(curM.cards) {
+			return curM.cards[curM.cardFocus].favorite, true
+		}
+		return weather.Favorite{}, false
+	}
+	if curM.curItem == nil {
+		return weather.Favorite{}, false
+	}
+	for _, fav := range curM.favorites {
+		if fav.Lat == curM.curItem.Lat && fav.Lon == curM.curItem.Lon {
+			return fav, true
+		}
+	}
+	return weather.Favorite{}, false
+}
+
+func loadFavorites(service *weather.WeatherService, initial bool) tea.Cmd {
+	return func() tea.Msg {
+		favorites, err := service.Favorites(context.Background())
+		return favoritesMsg{favorites: favorites, err: err, initial: initial}
+	}
+}
+
+// changeFavorites runs change and then reloads the favorites.
+func changeFavorites(service *weather.WeatherService, change func(context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		if err := change(context.Background()); err != nil {
+			return favoritesMsg{err: err}
+		}
+		return loadFavorites(service, false)()
+	}
+}
+
+// startDashboard fetches the weather for every favorite again, through the
+// service's worker pool, and schedules the next refresh.
+func (curM *StateModel) startDashboard() tea.Cmd {
+	curM.dashboardGen++
+	gen := curM.dashboardGen
+
+	cards := make([]dashboardCard, len(curM.favorites))
+	locs := make([]weather.Location, len(curM.favorites))
+	for i, fav := range curM.favorites {
+		cards[i] = dashboardCard{favorite: fav, loading: true}
+		// Keep showing what the card had until the new weather is in
+		for _, old := range curM.cards {
+			if old.favorite.Lat == fav.Lat && old.favorite.Lon == fav.Lon {
+				cards[i].weather, cards[i].err = old.weather, old.err
+			}
+		}
+		locs[i] = weather.Location{
+			Name:    fav.Name,
+			Country: fav.Country,
+			Coord:   weather.Coordinates{Lat: fav.Lat, Lon: fav.Lon},
+			Id:      fav.Id,
+		}
+	}
+	curM.cards = cards
+	curM.cardFocus = min(curM.cardFocus, max(len(cards)-1, 0))
+
+	tick := tea.Tick(dashboardRefresh, func(_ time.Time) tea.Msg {
+		return dashboardTickMsg{gen: gen}
+	})
+	if len(locs) == 0 {
+		return tick
+	}
+
+	service := curM.service
+	return tea.Batch(tick, func() tea.Msg {
+		results := service.GetWeatherAll(context.Background(), locs, weather.DashboardWorkers)
+		return waitForCard(gen, results)()
+	})
+}
+
+// waitForCard blocks until the next card's weather is in. Update starts it
+// again for every card until results is closed.
+func waitForCard(gen int, results <-chan weather.LocationWeather) tea.Cmd {
+	return func() tea.Msg {
+		res, ok := <-results
+		return dashboardMsg{gen: gen, result: res, results: results, done: !ok}
+	}
+}
+
+// refreshCards puts weather refreshed in the background on the cards for
+// coord.
+func (curM *StateModel) refreshCards(coord weather.Coordinates, w *weather.WeatherResponse) {
+	for i, card := range curM.cards {
+		if card.favorite.Lat == coord.Lat && card.favorite.Lon == coord.Lon {
+			curM.cards = slices.Clone(curM.cards)
+			curM.cards[i].weather = w
+			return
+		}
+	}
+}
+
+// markedCity is the location c marks for comparing: the focused card on the
+// dashboard, or else the location on screen.
+func (curM StateModel) markedCity() (weather.City, bool) {
+	if curM.isDashboardOpen {
+		if curM.cardFocus < len(curM.cards) {
+			return curM.cards[curM.cardFocus].favorite.City, true
+		}
+		return weather.City{}, false
+	}
+	if curM.curItem == nil {
+		return weather.City{}, false
+	}
+	return *curM.curItem, true
+}
+
+// toggleCompared marks the location for comparing, or unmarks it if it
+// already was. Marking one more than fits drops the one marked first.
+func (curM *StateModel) toggleCompared() {
+	city, ok := curM.markedCity()
+	if !ok {
+		return
+	}
+	i := slices.IndexFunc(curM.compareSet, func(c weather.City) bool {
+		return c.Lat == city.Lat && c.Lon == city.Lon
+	})
+	if i >= 0 {
+		curM.compareSet = slices.Delete(slices.Clone(curM.compareSet), i, i+1)
+		return
+	}
+	curM.compareSet = append(slices.Clone(curM.compareSet), city)
+	if len(curM.compareSet) > maxCompared {
+		curM.compareSet = curM.compareSet[1:]
+	}
+}
+
+// startCompare fetches the weather for every marked location.
+func (curM *StateModel) startCompare() tea.Cmd {
+	curM.compareGen++
+	curM.compared = nil
+	return curM.fetchCompared()
+}
+
+func (curM StateModel) fetchCompared() tea.Cmd {
+	if len(curM.compareSet) == 0 {
+		return nil
+	}
+	gen := curM.compareGen
+	locs := make([]weather.Location, len(curM.compareSet))
+	for i, city := range curM.compareSet {
+		locs[i] = weather.Location{
+			Name:    city.Name,
+			Country: city.Country,
+			Coord:   weather.Coordinates{Lat: city.Lat, Lon: city.Lon},
+			Id:      city.Id,
+		}
+	}
+
+	service := curM.service
+	return func() tea.Msg {
+		results := make([]weather.LocationWeather, len(locs))
+		for res := range service.GetWeatherAll(context.Background(), locs, weather.DashboardWorkers) {
+			results[res.Index] = res
+		}
+		return compareMsg{gen: gen, results: results}
+	}
+}
+
+// refreshCompared puts weather refreshed in the background on the compared
+// location at coord.
+func (curM *StateModel) refreshCompared(coord weather.Coordinates, w *weather.WeatherResponse) {
+	for i, city := range curM.compareSet {
+		if i < len(curM.compared) && city.Lat == coord.Lat && city.Lon == coord.Lon {
+			curM.compared = slices.Clone(curM.compared)
+			curM.compared[i].Weather, curM.compared[i].Err = w, nil
+			return
+		}
+	}
+}
+
+func (curM *StateModel) moveCardFocus(msg tea.KeyMsg) {
+	cols := dashboardColumns(curM.width)
+	by := 0
+	switch {
+	case key.Matches(msg, curM.keys.left):
+		by = -1
+	case key.Matches(msg, curM.keys.right):
+		by = 1
+	case key.Matches(msg, curM.keys.up):
+		by = -cols
+	case key.Matches(msg, curM.keys.down):
+		by = cols
+	}
+	if to := curM.cardFocus + by; to >= 0 && to < len(curM.cards) {
+		curM.cardFocus = to
+	}
+}
+
 func (curM *StateModel) debouncedSearch() tea.Cmd {
 	query := curM.textInput.Value()
 	curM.debounceId++
@@ -108,40 +495,126 @@ func (curM StateModel) performLocationSearch() tea.Cmd {
 	query := curM.textInput.Value()
 
 	return func() tea.Msg {
+		var cities []weather.City
+		var err error
 		if len(query) < 3 {
-			return citySearchResultMsg{locs: nil}
+			// Too short to search for; offer what was picked before
+			cities, err = curM.service.RecentCities(context.Background(), query, weather.CityPageSize)
+			if err != nil {
+				return citySearchResultMsg{locs: nil, query: query}
+			}
+			return citySearchResultMsg{locs: cityItems(cities), query: query}
 		}
-		cities, err := curM.service.ResolveCity(context.Background(), query)
+
+		cities, err = curM.service.SuggestCities(context.Background(), query)
 		if err != nil {
-			return citySearchResultMsg{locs: nil}
+			return citySearchResultMsg{locs: nil, query: query}
 		}
-		items := make([]list.Item, len(cities))
-		for i, city := range cities {
-			items[i] = city
+		return citySearchResultMsg{locs: cityItems(cities), query: query, more: len(cities) == weather.CityPageSize}
+	}
+}
+
+// nameLocation reverse geocodes a point the user picked, through the provider
+// if no known city is near.
+func nameLocation(service *weather.WeatherService, coord weather.Coordinates) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), reverseGeocodeTimeout)
+		defer cancel()
+		return locationNamedMsg{city: service.ReverseGeocode(ctx, coord)}
+	}
+}
+
+// submitLocationSearch looks up what was typed through the provider's
+// geocoding too, which typing alone never does.
+func (curM StateModel) submitLocationSearch() tea.Cmd {
+	query := curM.textInput.Value()
+	if len(query) < 3 {
+		return nil
+	}
+
+	service := curM.service
+	return func() tea.Msg {
+		cities, err := service.ResolveCity(context.Background(), query)
+		if err != nil {
+			log.Printf("error resolving %q: %s", query, err)
+			return citySearchResultMsg{locs: nil, query: query}
 		}
-		return citySearchResultMsg{locs: items}
+		return citySearchResultMsg{locs: cityItems(cities), query: query, more: len(cities) == weather.CityPageSize}
 	}
 }
 
+// loadMoreCities fetches the next page of search results once the cursor is
+// on the last one.
+func (curM *StateModel) loadMoreCities() tea.Cmd {
+	items := curM.searchResults.Items()
+	if !curM.hasMoreCities || curM.isLoadingMore || len(items) == 0 || curM.searchResults.Index() < len(items)-1 {
+		return nil
+	}
+	curM.isLoadingMore = true
+
+	service, query, offset := curM.service, curM.searchQuery, len(items)
+	return func() tea.Msg {
+		cities, err := service.SearchCities(context.Background(), query, weather.CityPageSize, offset)
+		if err != nil {
+			log.Printf("error loading more cities: %s", err)
+			return citySearchResultMsg{query: query, offset: offset}
+		}
+		return citySearchResultMsg{locs: cityItems(cities), query: query, offset: offset, more: len(cities) == weather.CityPageSize}
+	}
+}
+
+func cityItems(cities []weather.City) []list.Item {
+	items := make([]list.Item, len(cities))
+	for i, city := range cities {
+		items[i] = city
+	}
+	return items
+}
+
 func (curM StateModel) performWeatherSearch() tea.Cmd {
 	loc := curM.curItem
 	if loc == nil {
 		return nil
 	}
-	if loc.Lat == 0 && loc.Lon == 0 {
-		return nil
-	}
 	return func() tea.Msg {
-		coord := weather.Coordinates{Lat: curM.curItem.Lat, Lon: curM.curItem.Lon}
+		coord := weather.Coordinates{Lat: loc.Lat, Lon: loc.Lon}
 		res, err := curM.service.GetWeather(
 			context.Background(),
-			weather.Location{Name: curM.curItem.Name, Coord: coord, Id: curM.curItem.Id},
+			weather.Location{Name: loc.Name, Country: loc.Country, Coord: coord, Id: loc.Id},
 		)
 		log.Print("called get weather")
 		if err != nil {
 			log.Printf("error fetching the weather: %s", err)
-			return weatherSearchResultMsg{weather: nil}
+			return weatherSearchResultMsg{weather: nil, err: err, coord: coord}
+		}
+		return weatherSearchResultMsg{weather: res, coord: coord}
+	}
+}
+
+func (curM StateModel) performForecastSearch() tea.Cmd {
+	loc := curM.curItem
+	if loc == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		coord := weather.Coordinates{Lat: loc.Lat, Lon: loc.Lon}
+		res, err := curM.service.GetForecast(
+			context.Background(),
+			weather.Location{Name: loc.Name, Country: loc.Country, Coord: coord, Id: loc.Id},
+		)
+		if err != nil {
+			log.Printf("error fetching the forecast: %s", err)
+			return forecastSearchResultMsg{forecast: nil, coord: coord}
 		}
-		return weatherSearchResultMsg{weather: res}
+		return forecastSearchResultMsg{forecast: res, coord: coord}
+	}
+}
+
+// waitForRefresh blocks until the service has refreshed some weather in the
+// background. Update starts it again after every refresh it receives.
+func waitForRefresh(service *weather.WeatherService) tea.Cmd {
+	return func() tea.Msg {
+		update := <-service.Updates()
+		return weatherSearchResultMsg{weather: update.Weather, refresh: true, coord: update.Coord}
 	}
 }
@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"github/Arnab-cloud/tui_weather_app/internal/weather"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -10,16 +12,19 @@ func (curM StateModel) View() string {
 	var content string
 
 	helpView := curM.renderContextualHelp()
+	if len(curM.compareSet) > 0 && !curM.isCompareOpen && !curM.isFilterOpen {
+		helpView = lipgloss.JoinVertical(lipgloss.Left, renderCompareBar(curM.compareSet, curM.msgs), helpView)
+	}
 	height := max(curM.height-lipgloss.Height(helpView), 0)
 
 	if curM.err != nil {
 		content = windowStyle.
 			Width(curM.width).
 			Height(height).
-			Render(errorStyle.Render(fmt.Sprintf("❌ Error: %v", curM.err)))
+			Render(errorStyle.Render(fmt.Sprintf("❌ %s: %v", curM.msgs.get(msgError), curM.err)))
 	} else if curM.isFilterOpen {
 		searchContent := lipgloss.JoinVertical(lipgloss.Left,
-			titleStyle.Render("🌤️ Weather Search"),
+			titleStyle.Render("🌤️ "+curM.msgs.get(msgWeatherSearch)),
 			curM.textInput.View(),
 			"",
 			curM.searchResults.View(),
@@ -28,20 +33,51 @@ func (curM StateModel) View() string {
 			Width(curM.width).
 			Height(height).
 			Render(searchContent)
+	} else if curM.isCompareOpen {
+		content = renderCompare(curM.compareSet, curM.compared, curM.units, curM.msgs, curM.width, height)
+	} else if curM.isDashboardOpen {
+		content = renderDashboard(curM.cards, curM.cardFocus, curM.units, curM.msgs, curM.width, height)
 	} else if curM.curItem != nil && curM.curWeather != nil {
-		content = renderWeather(curM.curWeather, curM.width, height)
+		content = curM.renderWeatherView(height)
+	} else if curM.fetchErr != nil {
+		content = windowStyle.
+			Width(curM.width).
+			Height(height).
+			Render(errorStyle.Render(curM.renderFetchError()))
 	} else {
 		content = windowStyle.
 			Width(curM.width).
 			Height(height).
-			Render("Loading...")
+			Render(curM.msgs.get(msgLoading))
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, content, helpView)
 }
 
+// renderWeatherView is the weather on screen, with the favorites next to it
+// unless they are hidden.
+func (curM StateModel) renderWeatherView(height int) string {
+	if !curM.showFavorites || len(curM.favorites) == 0 {
+		return renderWeather(curM.curWeather, curM.curForecast, curM.units, curM.msgs, curM.width, height)
+	}
+
+	pane := renderFavorites(curM.favorites, curM.curItem, curM.msgs)
+	width := max(curM.width-lipgloss.Width(pane), 0)
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		pane,
+		renderWeather(curM.curWeather, curM.curForecast, curM.units, curM.msgs, width, height),
+	)
+}
+
+func (curM StateModel) renderFetchError() string {
+	if errors.Is(curM.fetchErr, weather.ErrInvalidAPIKey) {
+		return "🔑 " + curM.msgs.get(msgInvalidKey)
+	}
+	return fmt.Sprintf("⚠️ %s: %v", curM.msgs.get(msgFetchFailed), curM.fetchErr)
+}
+
 func (curM StateModel) renderContextualHelp() string {
-	contextualBindings := curM.keys.GetContextualHelp(curM.isFilterOpen, curM.textInput.Focused())
+	contextualBindings := curM.keys.GetContextualHelp(curM.isFilterOpen, curM.textInput.Focused(), curM.isDashboardOpen, curM.isCompareOpen)
 
 	helpKeys := &contextualKeyMap{bindings: contextualBindings}
 
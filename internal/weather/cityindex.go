@@ -0,0 +1,176 @@
+package weather
+
+import (
+	"container/heap"
+	"context"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+type indexedCity struct {
+	city       City
+	searchName string
+	grams      int
+	population int64
+}
+
+// cityIndex is an in-memory trigram index over the cities table. It ranks
+// exactly like the database search, without a round trip per keystroke.
+type cityIndex struct {
+	// mu guards cities and postings, which grow as cities are learned
+	mu       sync.RWMutex
+	cities   []indexedCity
+	postings map[string][]int32
+
+	// counts holds per-search scratch space, one counter per city
+	counts sync.Pool
+}
+
+type hit struct {
+	i     int32
+	score float64
+}
+
+// hitHeap keeps the best hits seen so far with the worst on top, so it can
+// be evicted cheaply.
+type hitHeap []hit
+
+func (h hitHeap) Len() int           { return len(h) }
+func (h hitHeap) Less(a, b int) bool { return worse(h[a], h[b]) }
+func (h hitHeap) Swap(a, b int)      { h[a], h[b] = h[b], h[a] }
+func (h *hitHeap) Push(x any)        { *h = append(*h, x.(hit)) }
+func (h *hitHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func worse(a, b hit) bool {
+	if a.score != b.score {
+		return a.score < b.score
+	}
+	return a.i > b.i
+}
+
+func newCityIndex(rows []database.City) *cityIndex {
+	ix := &cityIndex{
+		cities:   make([]indexedCity, len(rows)),
+		postings: make(map[string][]int32),
+	}
+
+	for i, row := range rows {
+		ix.cities[i] = ix.indexRow(int32(i), row)
+	}
+	ix.counts.New = func() any {
+		return make([]uint8, len(ix.cities))
+	}
+	return ix
+}
+
+func (ix *cityIndex) indexRow(i int32, row database.City) indexedCity {
+	grams := trigrams(row.SearchName)
+	for g := range grams {
+		ix.postings[g] = append(ix.postings[g], i)
+	}
+	return indexedCity{
+		city:       cityFromRow(row),
+		searchName: row.SearchName,
+		grams:      len(grams),
+		population: row.Population.Int64,
+	}
+}
+
+// add indexes cities stored after the index was built.
+func (ix *cityIndex) add(rows ...database.City) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, row := range rows {
+		ix.cities = append(ix.cities, ix.indexRow(int32(len(ix.cities)), row))
+	}
+}
+
+// search returns one page of the cities matching query, best first. It
+// reports false for queries too short to have a trigram.
+func (ix *cityIndex) search(query string, limit, offset int) ([]City, bool) {
+	query = database.NormalizeName(query)
+	grams := trigrams(query)
+	if len(grams) == 0 {
+		return nil, false
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	counts := ix.counts.Get().([]uint8)
+	if len(counts) < len(ix.cities) {
+		// Made before the index last grew
+		counts = make([]uint8, len(ix.cities))
+	}
+	defer ix.counts.Put(counts)
+
+	var touched []int32
+	for g := range grams {
+		for _, i := range ix.postings[g] {
+			if counts[i] == 0 {
+				touched = append(touched, i)
+			}
+			// A name can't share more trigrams than the query has, which
+			// keeps this from overflowing for any query shorter than 258 runes
+			counts[i]++
+		}
+	}
+
+	// Only the best offset+limit matter, so keep just those instead of
+	// sorting every match
+	keep := offset + limit
+	best := make(hitHeap, 0, keep)
+	for _, i := range touched {
+		c := &ix.cities[i]
+		h := hit{i, matchScore(int(counts[i]), len(grams), c.grams, strings.HasPrefix(c.searchName, query), c.population)}
+		counts[i] = 0
+
+		if h.score < minMatchScore {
+			continue
+		}
+		if len(best) < keep {
+			heap.Push(&best, h)
+		} else if keep > 0 && worse(best[0], h) {
+			best[0] = h
+			heap.Fix(&best, 0)
+		}
+	}
+
+	hits := make([]hit, len(best))
+	for n := len(best) - 1; n >= 0; n-- {
+		hits[n] = heap.Pop(&best).(hit)
+	}
+
+	if offset >= len(hits) {
+		return nil, true
+	}
+	hits = hits[offset:]
+
+	cities := make([]City, len(hits))
+	for i, h := range hits {
+		cities[i] = ix.cities[h.i].city
+	}
+	return cities, true
+}
+
+// LoadCityIndex reads every city into memory so that searches no longer go
+// to the database. Until it is done, they still do.
+func (s *WeatherService) LoadCityIndex(ctx context.Context) error {
+	start := time.Now()
+	rows, err := s.DB.ListCities(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.cityIndex.Store(newCityIndex(rows))
+	log.Printf("Indexed %d cities in %s", len(rows), time.Since(start))
+	return nil
+}
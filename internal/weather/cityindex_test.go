@@ -0,0 +1,169 @@
+package weather
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"github/Arnab-cloud/tui_weather_app/sql/schema"
+)
+
+// cityListPath is where BenchmarkCityIndexSearch looks for OpenWeather's
+// city.list.json, plain or gzipped. Download it from
+// https://bulk.openweathermap.org/sample/ to run the benchmark.
+const cityListPath = "testdata/city.list.json"
+
+func newTestService(tb testing.TB) *WeatherService {
+	tb.Helper()
+	ctx := context.Background()
+
+	conn, err := database.Open(filepath.Join(tb.TempDir(), "weather.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+
+	migrator, err := database.NewMigrator(conn, schema.FS)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		tb.Fatal(err)
+	}
+
+	s := NewWeatherService(conn, nil, "en")
+	tb.Cleanup(s.Close)
+	return s
+}
+
+func TestCityIndexRanksLikeTheDatabase(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	cities := []struct {
+		name       string
+		country    string
+		population int64
+	}{
+		{"New York", "US", 8336817},
+		{"York", "GB", 153717},
+		{"New Yorker Heights", "US", 0},
+		{"Newark", "US", 311549},
+		{"Yorkton", "CA", 16343},
+		{"San Francisco", "US", 873965},
+		{"San Diego", "US", 1386932},
+		{"Santiago", "CL", 5614000},
+		{"San José", "CR", 342188},
+		{"Santa Fe", "US", 87505},
+		{"Sankt Gallen", "CH", 75833},
+		{"Pisa", "IT", 90118},
+		{"São Paulo", "BR", 12325232},
+		{"Saint Paul", "US", 311527},
+		{"Springfield", "US", 169176},
+		{"Springfield Gardens", "US", 0},
+		{"Spring", "US", 62559},
+		{"Ciudad de México", "MX", 9209944},
+		{"Mexicali", "MX", 1049792},
+		{"Londonderry", "GB", 85016},
+		{"London", "GB", 8961989},
+		{"London", "CA", 422324},
+		{"New London", "US", 27367},
+	}
+	for i, c := range cities {
+		err := s.DB.UpsertCity(ctx, database.UpsertCityParams{
+			ID:         int64(i + 1),
+			Name:       c.name,
+			Country:    c.country,
+			Lat:        float64(i),
+			Lon:        float64(i),
+			Population: sql.NullInt64{Int64: c.population, Valid: c.population > 0},
+			SearchName: database.NormalizeName(c.name),
+			Source:     database.CitySourceBundled,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := s.DB.ListCities(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := newCityIndex(rows)
+
+	queries := []string{"new york", "nwe york", "york", "san", "santa", "sao paulo", "saint", "springfeld", "mexico", "london", "ondon", "xyzzy"}
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			want, err := s.rankedCities(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := idx.search(query, len(cities), 0)
+			if !ok {
+				t.Fatalf("search(%q) reported the query too short", query)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("search(%q) found %d cities, the database %d: %v", query, len(got), len(want), got)
+			}
+			for i := range want {
+				if int64(got[i].Id) != want[i].ID {
+					t.Errorf("search(%q)[%d] = %s, the database ranks %s there", query, i, got[i].Place(), want[i].Name)
+				}
+			}
+		})
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		all, _ := idx.search("san", len(cities), 0)
+		for offset := 0; offset < len(all); offset += 2 {
+			page, _ := idx.search("san", 2, offset)
+			for i, city := range page {
+				if city.Id != all[offset+i].Id {
+					t.Errorf("page at %d has %s at %d, want %s", offset, city.Name, i, all[offset+i].Name)
+				}
+			}
+		}
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		if _, ok := idx.search("ny", CityPageSize, 0); ok {
+			t.Error("search of a query without a trigram should be left to the database")
+		}
+	})
+}
+
+func BenchmarkCityIndexSearch(b *testing.B) {
+	path := cityListPath
+	if _, err := os.Stat(path); err != nil {
+		path += ".gz"
+	}
+	if _, err := os.Stat(path); err != nil {
+		b.Skipf("%s not found; download it from https://bulk.openweathermap.org/sample/", cityListPath)
+	}
+
+	ctx := context.Background()
+	s := newTestService(b)
+	if _, err := database.NewCitySeeder(s.conn).ImportFile(ctx, path, 5000, nil); err != nil {
+		b.Fatal(err)
+	}
+	rows, err := s.DB.ListCities(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	idx := newCityIndex(rows)
+	b.Logf("indexed %d cities", len(rows))
+
+	for _, query := range []string{"lon", "london", "nwe york", "san", "springfield", "ciudad de mexico"} {
+		b.Run(query, func(b *testing.B) {
+			for b.Loop() {
+				if _, ok := idx.search(query, CityPageSize, 0); !ok {
+					b.Fatalf("search(%q) reported the query too short", query)
+				}
+			}
+		})
+	}
+}
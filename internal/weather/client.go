@@ -3,8 +3,11 @@ package weather
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"time"
@@ -13,20 +16,99 @@ import (
 type WeatherClient struct {
 	HTTPClient  *http.Client
 	WeatherURL  string
+	ForecastURL string
 	GeocoderURL string
 	APIKey      string
 }
 
-func NewWeatherClient(apiKey, weatherURL, geocodeURL string) *WeatherClient {
+func NewWeatherClient(apiKey, weatherURL, forecastURL, geocodeURL string) *WeatherClient {
 	return &WeatherClient{
 		HTTPClient:  &http.Client{Timeout: 10 * time.Minute},
 		WeatherURL:  weatherURL,
+		ForecastURL: forecastURL,
 		GeocoderURL: geocodeURL,
 		APIKey:      apiKey,
 	}
 }
 
+func (c *WeatherClient) Name() string { return "OpenWeatherMap" }
+
+// RetryPolicy bounds how FetchAndDecode retries transient failures: network
+// errors, 429s and 5xx answers.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// delay returns how long to wait before the given retry, or false when the
+// server asked us to back off for longer than we are willing to wait.
+func (p RetryPolicy) delay(retry int, lastErr error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, apiErr.RetryAfter <= p.MaxDelay
+	}
+
+	// Exponential backoff with equal jitter
+	backoff := min(p.BaseDelay<<(retry-1), p.MaxDelay)
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
 func FetchAndDecode[T any](client *http.Client, req *http.Request) (*T, error) {
+	ctx := req.Context()
+	policy := DefaultRetryPolicy
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay, ok := policy.delay(attempt, lastErr)
+			if !ok {
+				break
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		result, err := fetchAndDecodeOnce[T](client, req)
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+		if !isRetryable(ctx, err) {
+			break
+		}
+		log.Printf("retrying %s after: %s", req.URL.Host, err)
+	}
+
+	return nil, lastErr
+}
+
+func fetchAndDecodeOnce[T any](client *http.Client, req *http.Request) (*T, error) {
 	response, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -36,7 +118,7 @@ func FetchAndDecode[T any](client *http.Client, req *http.Request) (*T, error) {
 
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
 		body, _ := io.ReadAll(response.Body)
-		return nil, fmt.Errorf("Api Error: status=%d, body=%s", response.StatusCode, body)
+		return nil, newAPIError(response, body)
 	}
 
 	var result T
@@ -47,7 +129,7 @@ func FetchAndDecode[T any](client *http.Client, req *http.Request) (*T, error) {
 	return &result, nil
 }
 
-func (c *WeatherClient) FetchWeather(ctx context.Context, lat, lon float64) (*WeatherResponse, error) {
+func (c *WeatherClient) FetchWeather(ctx context.Context, lat, lon float64, lang string) (*WeatherResponse, error) {
 	weatherUrl, err := url.Parse(c.WeatherURL)
 	if err != nil {
 		return nil, err
@@ -57,7 +139,10 @@ func (c *WeatherClient) FetchWeather(ctx context.Context, lat, lon float64) (*We
 	query.Set("lat", fmt.Sprintf("%f", lat))
 	query.Set("lon", fmt.Sprintf("%f", lon))
 	query.Set("appid", c.APIKey)
-	query.Set("units", "metric")
+	query.Set("units", CanonicalUnits)
+	if lang != "" {
+		query.Set("lang", lang)
+	}
 
 	weatherUrl.RawQuery = query.Encode()
 	req, err := http.NewRequestWithContext(ctx, "GET", weatherUrl.String(), nil)
@@ -65,10 +150,48 @@ func (c *WeatherClient) FetchWeather(ctx context.Context, lat, lon float64) (*We
 		return nil, fmt.Errorf("Error forming the weather request: %s", err)
 	}
 
-	return FetchAndDecode[WeatherResponse](c.HTTPClient, req)
+	w, err := FetchAndDecode[WeatherResponse](c.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	w.Provider = c.Name()
+	w.Lang = lang
+
+	return w, nil
+}
+
+func (c *WeatherClient) FetchForecast(ctx context.Context, lat, lon float64, lang string) (*ForecastResponse, error) {
+	forecastUrl, err := url.Parse(c.ForecastURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := forecastUrl.Query()
+	query.Set("lat", fmt.Sprintf("%f", lat))
+	query.Set("lon", fmt.Sprintf("%f", lon))
+	query.Set("appid", c.APIKey)
+	query.Set("units", CanonicalUnits)
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+
+	forecastUrl.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", forecastUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error forming the forecast request: %s", err)
+	}
+
+	f, err := FetchAndDecode[ForecastResponse](c.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	f.Provider = c.Name()
+	f.Lang = lang
+
+	return f, nil
 }
 
-func (c *WeatherClient) FetchGeocoding(ctx context.Context, cityName string, limit int) ([]City, error) {
+func (c *WeatherClient) FetchGeocoding(ctx context.Context, cityName string, limit int, lang string) ([]City, error) {
 	geocoderUrl, err := url.Parse(fmt.Sprintf("%s/direct", c.GeocoderURL))
 	if err != nil {
 		return nil, err
@@ -91,7 +214,14 @@ func (c *WeatherClient) FetchGeocoding(ctx context.Context, cityName string, lim
 	}
 
 	if len(*cities) == 0 {
-		return nil, fmt.Errorf("no cities found with name: %s", cityName)
+		return nil, fmt.Errorf("no cities found with name %s: %w", cityName, ErrNoResults)
+	}
+
+	// The direct geocoding API has no lang parameter, but it does return local names
+	for i, city := range *cities {
+		if localName, ok := city.LocalNames[lang]; ok && localName != "" {
+			(*cities)[i].Name = localName
+		}
 	}
 
 	return *cities, nil
@@ -121,7 +251,7 @@ func (c *WeatherClient) FetchReverseGeocoding(ctx context.Context, coord Coordin
 	}
 
 	if len(*cities) == 0 {
-		return nil, fmt.Errorf("no cities found with coordinates: %v", coord)
+		return nil, fmt.Errorf("no cities found with coordinates %v: %w", coord, ErrNoResults)
 	}
 
 	return *cities, nil
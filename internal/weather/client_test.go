@@ -0,0 +1,152 @@
+package weather
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	Value string `json:"value"`
+}
+
+// retryServer answers with the given statuses in turn, then with a payload.
+// It returns how many requests it got.
+func retryServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[n-1])
+			w.Write([]byte(`{"cod": 0, "message": "try again"}`))
+			return
+		}
+		w.Write([]byte(`{"value": "ok"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func withRetryPolicy(t *testing.T, policy RetryPolicy) {
+	t.Helper()
+	old := DefaultRetryPolicy
+	DefaultRetryPolicy = policy
+	t.Cleanup(func() { DefaultRetryPolicy = old })
+}
+
+func fetchPayload(t *testing.T, srv *httptest.Server) (*testPayload, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return FetchAndDecode[testPayload](srv.Client(), req)
+}
+
+func TestFetchAndDecodeRetriesServerErrors(t *testing.T) {
+	withRetryPolicy(t, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	srv, requests := retryServer(t, "", http.StatusInternalServerError, http.StatusBadGateway)
+
+	got, err := fetchPayload(t, srv)
+	if err != nil {
+		t.Fatalf("FetchAndDecode after two 5xx: %s", err)
+	}
+	if got.Value != "ok" || requests.Load() != 3 {
+		t.Errorf("got %+v after %d requests, want ok after 3", got, requests.Load())
+	}
+}
+
+func TestFetchAndDecodeStopsAfterMaxAttempts(t *testing.T) {
+	withRetryPolicy(t, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	srv, requests := retryServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	_, err := fetchPayload(t, srv)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the last 503", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("%d requests, want 2", requests.Load())
+	}
+}
+
+func TestFetchAndDecodeHonoursRetryAfter(t *testing.T) {
+	withRetryPolicy(t, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second})
+	srv, requests := retryServer(t, "1", http.StatusTooManyRequests)
+
+	start := time.Now()
+	got, err := fetchPayload(t, srv)
+	if err != nil {
+		t.Fatalf("FetchAndDecode after a 429: %s", err)
+	}
+	if got.Value != "ok" || requests.Load() != 2 {
+		t.Errorf("got %+v after %d requests, want ok after 2", got, requests.Load())
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("retried after %s, the server asked for 1s", waited)
+	}
+}
+
+func TestFetchAndDecodeGivesUpOnLongRetryAfter(t *testing.T) {
+	withRetryPolicy(t, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+	srv, requests := retryServer(t, "120", http.StatusTooManyRequests)
+
+	start := time.Now()
+	_, err := fetchPayload(t, srv)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want the 429", err)
+	}
+	if apiErr.RetryAfter != 2*time.Minute {
+		t.Errorf("RetryAfter = %s, want 2m", apiErr.RetryAfter)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("%d requests, want 1", requests.Load())
+	}
+	if waited := time.Since(start); waited > DefaultRetryPolicy.MaxDelay {
+		t.Errorf("gave up after %s", waited)
+	}
+}
+
+func TestFetchAndDecodeInvalidAPIKey(t *testing.T) {
+	withRetryPolicy(t, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	srv, requests := retryServer(t, "", http.StatusUnauthorized)
+
+	_, err := fetchPayload(t, srv)
+	if !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("err = %v, want ErrInvalidAPIKey", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("%d requests, want 1: a bad key is not worth retrying", requests.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"30", 30 * time.Second},
+		{"soon", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 58*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %s, want about a minute", date, got)
+	}
+}
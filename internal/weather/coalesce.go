@@ -0,0 +1,52 @@
+package weather
+
+import (
+	"fmt"
+	"sync"
+)
+
+type flight[T any] struct {
+	wg  sync.WaitGroup
+	val T
+	err error
+}
+
+// flightGroup makes sure only one call per key is in flight at a time.
+// Callers that arrive while it runs wait for it and share its result.
+type flightGroup[T any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[T]
+}
+
+func (g *flightGroup[T]) Do(key string, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight[T])
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		f.wg.Wait()
+		return f.val, f.err
+	}
+
+	f := &flight[T]{}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	f.val, f.err = fn()
+	f.wg.Done()
+
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+
+	return f.val, f.err
+}
+
+// coordKey rounds coordinates to about a kilometer, so requests for the same
+// spot share one upstream call. Requests a little further apart, that the
+// cache radius would still answer with one row, may each make their own.
+func coordKey(coord Coordinates) string {
+	return fmt.Sprintf("%.2f,%.2f", coord.Lat, coord.Lon)
+}
@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// getWeatherTogether has callers ask for the weather at loc while p holds
+// the first call, and lets it answer once the rest had time to join.
+func getWeatherTogether(t *testing.T, s *WeatherService, p *fakeProvider, loc Location, callers int) ([]*WeatherResponse, []error) {
+	t.Helper()
+	results := make([]*WeatherResponse, callers)
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Go(func() {
+			results[i], errs[i] = s.GetWeather(context.Background(), loc)
+		})
+	}
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if calls, _ := p.calls(); calls > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the provider was never called")
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(p.release)
+	wg.Wait()
+	return results, errs
+}
+
+func TestConcurrentCallersShareOneFetch(t *testing.T) {
+	p := &fakeProvider{name: "slow", release: make(chan struct{})}
+	s := newTestService(t)
+	s.Client = p
+
+	results, errs := getWeatherTogether(t, s, p, Location{Name: "Paris", Coord: Coordinates{Lat: 48.8566, Lon: 2.3522}}, 10)
+	if calls, _ := p.calls(); calls != 1 {
+		t.Errorf("provider called %d times, want once", calls)
+	}
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %s", i, errs[i])
+		}
+		if results[i] != results[0] {
+			t.Errorf("caller %d got a different answer than the first", i)
+		}
+	}
+}
+
+func TestConcurrentCallersShareOneFailure(t *testing.T) {
+	p := &fakeProvider{name: "slow", release: make(chan struct{}), err: errUnavailable}
+	s := newTestService(t)
+	s.Client = p
+
+	_, errs := getWeatherTogether(t, s, p, Location{Name: "Paris", Coord: Coordinates{Lat: 48.8566, Lon: 2.3522}}, 10)
+	if calls, _ := p.calls(); calls != 1 {
+		t.Errorf("provider called %d times, want once", calls)
+	}
+	for i, err := range errs {
+		if !errors.Is(err, errUnavailable) {
+			t.Errorf("caller %d: err = %v, want the shared failure", i, err)
+		}
+	}
+}
+
+func TestFlightGroupKeysAreSeparate(t *testing.T) {
+	var g flightGroup[int]
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	var wg sync.WaitGroup
+	got := make([]int, 2)
+	for i, key := range []string{"a", "b"} {
+		wg.Go(func() {
+			got[i], _ = g.Do(key, func() (int, error) {
+				started <- struct{}{}
+				<-release
+				return i, nil
+			})
+		})
+	}
+
+	// Neither waits for the other
+	<-started
+	<-started
+	close(release)
+	wg.Wait()
+	if got[0] != 0 || got[1] != 1 {
+		t.Errorf("results = %v, want each key its own", got)
+	}
+}
+
+func TestCoordKey(t *testing.T) {
+	a := Coordinates{Lat: 48.8566, Lon: 2.3522}
+	if coordKey(a) != coordKey(Coordinates{Lat: 48.8571, Lon: 2.3519}) {
+		t.Error("points metres apart should share a key")
+	}
+	if coordKey(a) == coordKey(Coordinates{Lat: 48.8766, Lon: 2.3522}) {
+		t.Error("points kilometres apart should not share a key")
+	}
+}
@@ -0,0 +1,112 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// coordPart matches one latitude or longitude: decimal degrees, or degrees
+// with minutes and seconds, each with an optional hemisphere letter.
+const coordPart = `([+-]?\d+(?:\.\d+)?)\s*(?:[°º]\s*(?:(\d+(?:\.\d+)?)\s*['′]\s*(?:(\d+(?:\.\d+)?)\s*(?:["″]|'')\s*)?)?)?([NSEWnsew])?`
+
+var coordPattern = regexp.MustCompile(`^` + coordPart + `\s*(?:[,;]\s*|\s+)` + coordPart + `$`)
+
+// ParseCoordinates reads a point typed into the search box, such as
+// "48.85, 2.35", "48°51'N 2°21'E" or "geo:48.85,2.35". It reports false for
+// anything else, city names included.
+func ParseCoordinates(s string) (Coordinates, bool) {
+	s = strings.TrimSpace(s)
+
+	if rest, ok := cutPrefixFold(s, "geo:"); ok {
+		// geo:lat,lon[,alt][;crs=...;u=...][?q=...], see RFC 5870
+		rest, _, _ = strings.Cut(rest, "?")
+		rest, _, _ = strings.Cut(rest, ";")
+		parts := strings.Split(rest, ",")
+		if len(parts) < 2 || len(parts) > 3 {
+			return Coordinates{}, false
+		}
+		lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err1 != nil || err2 != nil {
+			return Coordinates{}, false
+		}
+		return validCoordinates(lat, lon)
+	}
+
+	m := coordPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Coordinates{}, false
+	}
+	lat, latHemi, ok1 := parseCoordPart(m[1:5])
+	lon, lonHemi, ok2 := parseCoordPart(m[5:9])
+	if !ok1 || !ok2 {
+		return Coordinates{}, false
+	}
+
+	// "2°21'E 48°51'N" names the longitude first
+	if strings.ContainsAny(latHemi, "EW") && strings.ContainsAny(lonHemi, "NS") {
+		lat, lon = lon, lat
+		latHemi, lonHemi = lonHemi, latHemi
+	}
+	if strings.ContainsAny(latHemi, "EW") || strings.ContainsAny(lonHemi, "NS") {
+		return Coordinates{}, false
+	}
+	return validCoordinates(lat, lon)
+}
+
+// parseCoordPart turns the degrees, minutes, seconds and hemisphere matched
+// by coordPart into signed decimal degrees.
+func parseCoordPart(m []string) (float64, string, bool) {
+	deg, err := strconv.ParseFloat(m[0], 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	negative := strings.HasPrefix(m[0], "-")
+	value := math.Abs(deg)
+	for i, unit := range []float64{60, 3600} {
+		if m[i+1] == "" {
+			continue
+		}
+		part, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil || part >= 60 {
+			return 0, "", false
+		}
+		value += part / unit
+	}
+
+	hemi := strings.ToUpper(m[3])
+	if hemi == "S" || hemi == "W" {
+		if negative {
+			// "-48°S" is ambiguous
+			return 0, "", false
+		}
+		negative = true
+	}
+	if negative {
+		value = -value
+	}
+	return value, hemi, true
+}
+
+func validCoordinates(lat, lon float64) (Coordinates, bool) {
+	if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return Coordinates{}, false
+	}
+	return Coordinates{Lat: lat, Lon: lon}, true
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// FormatCoordinates is how a point without a better name is shown.
+func FormatCoordinates(coord Coordinates) string {
+	return fmt.Sprintf("%.4f, %.4f", coord.Lat, coord.Lon)
+}
@@ -0,0 +1,91 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Coordinates
+		ok   bool
+	}{
+		{"48.8566, 2.3522", Coordinates{48.8566, 2.3522}, true},
+		{"48.8566 2.3522", Coordinates{48.8566, 2.3522}, true},
+		{"48.8566;2.3522", Coordinates{48.8566, 2.3522}, true},
+		{"-33.8688, 151.2093", Coordinates{-33.8688, 151.2093}, true},
+		{"+40.7128, -74.0060", Coordinates{40.7128, -74.006}, true},
+		{"  0, 0  ", Coordinates{0, 0}, true},
+		{"0.0000, 0.0000", Coordinates{0, 0}, true},
+
+		// Degrees, minutes and seconds
+		{"48°51'N 2°21'E", Coordinates{48.85, 2.35}, true},
+		{"48°51′24″N, 2°21′08″E", Coordinates{48.856667, 2.352222}, true},
+		{`33°52'08"S 151°12'31"E`, Coordinates{-33.868889, 151.208611}, true},
+		{"40º42'46''N 74º00'22''W", Coordinates{40.712778, -74.006111}, true},
+		{"48°N 2°E", Coordinates{48, 2}, true},
+		{"48°60'N 2°E", Coordinates{}, false},
+		{"48°51'60\"N 2°E", Coordinates{}, false},
+
+		// Hemisphere letters
+		{"48.85N 2.35E", Coordinates{48.85, 2.35}, true},
+		{"33.87s 151.21e", Coordinates{-33.87, 151.21}, true},
+		{"40.71N 74.01W", Coordinates{40.71, -74.01}, true},
+		{"-48°S 2°E", Coordinates{}, false},
+		{"48N 2N", Coordinates{}, false},
+		{"48E 2E", Coordinates{}, false},
+
+		// Longitude first
+		{"2°21'E 48°51'N", Coordinates{48.85, 2.35}, true},
+		{"151.21E, 33.87S", Coordinates{-33.87, 151.21}, true},
+
+		// geo: URIs
+		{"geo:48.8566,2.3522", Coordinates{48.8566, 2.3522}, true},
+		{"GEO:48.8566,2.3522", Coordinates{48.8566, 2.3522}, true},
+		{"geo:48.8566,2.3522,35", Coordinates{48.8566, 2.3522}, true},
+		{"geo:48.8566,2.3522;crs=wgs84;u=10", Coordinates{48.8566, 2.3522}, true},
+		{"geo:48.8566,2.3522?q=Paris", Coordinates{48.8566, 2.3522}, true},
+		{"geo:0,0?q=Null+Island", Coordinates{0, 0}, true},
+		{"geo:48.8566", Coordinates{}, false},
+		{"geo:48.8566,2.3522,35,1", Coordinates{}, false},
+		{"geo:north,east", Coordinates{}, false},
+		{"geo:91,0", Coordinates{}, false},
+
+		// Out of range
+		{"90, 180", Coordinates{90, 180}, true},
+		{"-90, -180", Coordinates{-90, -180}, true},
+		{"90.1, 0", Coordinates{}, false},
+		{"0, 180.5", Coordinates{}, false},
+		{"-91, 0", Coordinates{}, false},
+		{"91°N 0°E", Coordinates{}, false},
+
+		// Not coordinates
+		{"", Coordinates{}, false},
+		{"Paris", Coordinates{}, false},
+		{"New York", Coordinates{}, false},
+		{"48.8566", Coordinates{}, false},
+		{"48.8566, 2.3522, 35", Coordinates{}, false},
+		{"Route 66, 2", Coordinates{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCoordinates(tt.in)
+		if ok != tt.ok {
+			t.Errorf("ParseCoordinates(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if math.Abs(got.Lat-tt.want.Lat) > 1e-6 || math.Abs(got.Lon-tt.want.Lon) > 1e-6 {
+			t.Errorf("ParseCoordinates(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCoordinatesParses(t *testing.T) {
+	for _, coord := range []Coordinates{{0, 0}, {48.8566, 2.3522}, {-33.8688, -151.2093}, {90, 180}} {
+		got, ok := ParseCoordinates(FormatCoordinates(coord))
+		if !ok || got != coord {
+			t.Errorf("ParseCoordinates(FormatCoordinates(%v)) = %v, %v", coord, got, ok)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package weather
+
+import (
+	"context"
+	"sync"
+)
+
+// DashboardWorkers is how many locations GetWeatherAll looks up at once.
+const DashboardWorkers = 4
+
+// LocationWeather is the weather for the location at Index of the slice
+// passed to GetWeatherAll, or why there is none.
+type LocationWeather struct {
+	Index   int
+	Weather *WeatherResponse
+	Err     error
+}
+
+// GetWeatherAll looks up the weather for every location, at most workers at
+// a time, and sends each result as soon as it is in. The channel is closed
+// once all are done, or early if ctx is done. It is buffered for every
+// result, so nobody has to read it to the end.
+func (s *WeatherService) GetWeatherAll(ctx context.Context, locs []Location, workers int) <-chan LocationWeather {
+	results := make(chan LocationWeather, len(locs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(locs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				w, err := s.GetWeather(ctx, locs[i])
+				results <- LocationWeather{Index: i, Weather: w, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range locs {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
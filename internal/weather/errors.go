@@ -0,0 +1,88 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidAPIKey matches any APIError the provider answered with 401, so
+// callers can tell the user to fix their key instead of retrying.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIError is a non-2xx answer from a weather or geocoding API.
+type APIError struct {
+	StatusCode int
+	// Code and Message are the provider's own error code and text, when the
+	// body carried them.
+	Code       string
+	Message    string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("api error: status=%d, code=%s, message=%s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("api error: status=%d, body=%s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrInvalidAPIKey && e.StatusCode == http.StatusUnauthorized
+}
+
+// Temporary reports whether the same request may succeed if tried again later.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// providerErrorBody covers the error bodies of OpenWeatherMap
+// ({"cod": 401, "message": "..."}) and Open-Meteo ({"error": true, "reason": "..."}).
+type providerErrorBody struct {
+	Cod     json.RawMessage `json:"cod"`
+	Message string          `json:"message"`
+	Reason  string          `json:"reason"`
+}
+
+func newAPIError(response *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+	}
+
+	var parsed providerErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		// cod is a number in some OpenWeatherMap responses and a string in others
+		if code, err := strconv.Unquote(string(parsed.Cod)); err == nil {
+			apiErr.Code = code
+		} else {
+			apiErr.Code = string(parsed.Cod)
+		}
+
+		apiErr.Message = parsed.Message
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.Reason
+		}
+	}
+
+	return apiErr
+}
+
+// parseRetryAfter accepts both forms of the header: delay-seconds and an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
@@ -0,0 +1,229 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AttemptTimeout   = 10 * time.Second
+	ProviderCooldown = 2 * time.Minute
+
+	healthWindow       = 10
+	healthMinSamples   = 3
+	healthMaxErrorRate = 0.5
+)
+
+type outcome struct {
+	failed  bool
+	latency time.Duration
+}
+
+// providerHealth keeps the last few outcomes of one provider. A provider whose
+// error rate crosses healthMaxErrorRate is benched until its cooldown ends.
+type providerHealth struct {
+	mu            sync.Mutex
+	outcomes      []outcome
+	cooldownUntil time.Time
+}
+
+func (h *providerHealth) record(failed bool, latency time.Duration, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.outcomes = append(h.outcomes, outcome{failed: failed, latency: latency})
+	if len(h.outcomes) > healthWindow {
+		h.outcomes = h.outcomes[len(h.outcomes)-healthWindow:]
+	}
+
+	if !failed {
+		return
+	}
+	if len(h.outcomes) >= healthMinSamples && h.errorRate() >= healthMaxErrorRate {
+		h.cooldownUntil = time.Now().Add(cooldown)
+		// Start over after the cooldown so one bad streak isn't held against it forever
+		h.outcomes = h.outcomes[:0]
+	}
+}
+
+func (h *providerHealth) errorRate() float64 {
+	if len(h.outcomes) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, o := range h.outcomes {
+		if o.failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(h.outcomes))
+}
+
+func (h *providerHealth) healthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Now().After(h.cooldownUntil)
+}
+
+func (h *providerHealth) stats() ProviderStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var total time.Duration
+	for _, o := range h.outcomes {
+		total += o.latency
+	}
+
+	stats := ProviderStats{
+		ErrorRate:     h.errorRate(),
+		CooldownUntil: h.cooldownUntil,
+		Samples:       len(h.outcomes),
+	}
+	if len(h.outcomes) > 0 {
+		stats.AvgLatency = total / time.Duration(len(h.outcomes))
+	}
+	return stats
+}
+
+// ProviderStats is a snapshot of one provider's recent health.
+type ProviderStats struct {
+	Name          string
+	ErrorRate     float64
+	AvgLatency    time.Duration
+	CooldownUntil time.Time
+	Samples       int
+}
+
+// ProviderChain tries its providers in order and moves on to the next one
+// when a call fails. It is itself a Provider, so the service doesn't need
+// to know whether it talks to one backend or several.
+type ProviderChain struct {
+	Cooldown       time.Duration
+	AttemptTimeout time.Duration
+
+	providers []Provider
+	health    []*providerHealth
+}
+
+func NewProviderChain(providers ...Provider) *ProviderChain {
+	health := make([]*providerHealth, len(providers))
+	for i := range health {
+		health[i] = &providerHealth{}
+	}
+
+	return &ProviderChain{
+		Cooldown:       ProviderCooldown,
+		AttemptTimeout: AttemptTimeout,
+		providers:      providers,
+		health:         health,
+	}
+}
+
+func (c *ProviderChain) Name() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, " → ")
+}
+
+func (c *ProviderChain) Stats() []ProviderStats {
+	stats := make([]ProviderStats, len(c.providers))
+	for i, p := range c.providers {
+		stats[i] = c.health[i].stats()
+		stats[i].Name = p.Name()
+	}
+	return stats
+}
+
+// countsAgainstHealth reports whether err says something about the provider
+// rather than about the request. A rejected key does: that provider won't
+// work until the user fixes their configuration.
+func countsAgainstHealth(err error) bool {
+	if errors.Is(err, ErrNotSupported) || errors.Is(err, ErrNoResults) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary() || errors.Is(apiErr, ErrInvalidAPIKey) || apiErr.StatusCode == http.StatusForbidden
+	}
+	return true
+}
+
+func tryProviders[T any](ctx context.Context, c *ProviderChain, call func(context.Context, Provider) (T, error)) (T, error) {
+	var zero T
+
+	candidates := make([]int, 0, len(c.providers))
+	for i := range c.providers {
+		if c.health[i].healthy() {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		// Everything is cooling down; trying anyway beats showing nothing
+		log.Printf("all weather providers are unhealthy, trying them anyway")
+		for i := range c.providers {
+			candidates = append(candidates, i)
+		}
+	}
+
+	var errs []error
+	for _, i := range candidates {
+		p := c.providers[i]
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.AttemptTimeout)
+		start := time.Now()
+		res, err := call(attemptCtx, p)
+		latency := time.Since(start)
+		cancel()
+
+		if err == nil {
+			c.health[i].record(false, latency, c.Cooldown)
+			return res, nil
+		}
+
+		// The caller gave up; that's not the provider's fault
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+
+		if countsAgainstHealth(err) {
+			c.health[i].record(true, latency, c.Cooldown)
+		}
+		log.Printf("provider %s failed: %s", p.Name(), err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+
+	return zero, errors.Join(errs...)
+}
+
+func (c *ProviderChain) FetchWeather(ctx context.Context, lat, lon float64, lang string) (*WeatherResponse, error) {
+	return tryProviders(ctx, c, func(ctx context.Context, p Provider) (*WeatherResponse, error) {
+		return p.FetchWeather(ctx, lat, lon, lang)
+	})
+}
+
+func (c *ProviderChain) FetchForecast(ctx context.Context, lat, lon float64, lang string) (*ForecastResponse, error) {
+	return tryProviders(ctx, c, func(ctx context.Context, p Provider) (*ForecastResponse, error) {
+		return p.FetchForecast(ctx, lat, lon, lang)
+	})
+}
+
+func (c *ProviderChain) FetchGeocoding(ctx context.Context, cityName string, limit int, lang string) ([]City, error) {
+	return tryProviders(ctx, c, func(ctx context.Context, p Provider) ([]City, error) {
+		return p.FetchGeocoding(ctx, cityName, limit, lang)
+	})
+}
+
+func (c *ProviderChain) FetchReverseGeocoding(ctx context.Context, coord Coordinates, limit int) ([]City, error) {
+	return tryProviders(ctx, c, func(ctx context.Context, p Provider) ([]City, error) {
+		return p.FetchReverseGeocoding(ctx, coord, limit)
+	})
+}
@@ -0,0 +1,139 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var errUnavailable = &APIError{StatusCode: http.StatusServiceUnavailable}
+
+func fetchFrom(t *testing.T, c *ProviderChain) (string, error) {
+	t.Helper()
+	w, err := c.FetchWeather(context.Background(), 1, 2, "en")
+	if err != nil {
+		return "", err
+	}
+	return w.Provider, nil
+}
+
+func TestProviderChainFailsOver(t *testing.T) {
+	first := &fakeProvider{name: "first", err: errUnavailable}
+	second := &fakeProvider{name: "second"}
+	c := NewProviderChain(first, second)
+
+	got, err := fetchFrom(t, c)
+	if err != nil || got != "second" {
+		t.Fatalf("answered by %q, %v; want second", got, err)
+	}
+
+	second.setErr(errUnavailable)
+	_, err = fetchFrom(t, c)
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("err = %v, want both failures joined", err)
+	}
+}
+
+func TestProviderChainBenchesFailingProvider(t *testing.T) {
+	first := &fakeProvider{name: "first", err: errUnavailable}
+	second := &fakeProvider{name: "second"}
+	c := NewProviderChain(first, second)
+	c.Cooldown = 50 * time.Millisecond
+
+	for range healthMinSamples {
+		fetchFrom(t, c)
+	}
+	if calls, _ := first.calls(); calls != healthMinSamples {
+		t.Fatalf("first called %d times, want %d", calls, healthMinSamples)
+	}
+	if c.health[0].healthy() {
+		t.Fatal("first should be cooling down after failing every time")
+	}
+
+	// Skipped while it cools down
+	fetchFrom(t, c)
+	if calls, _ := first.calls(); calls != healthMinSamples {
+		t.Errorf("first called %d times during its cooldown, want %d", calls, healthMinSamples)
+	}
+
+	// And tried first again once it is over
+	time.Sleep(c.Cooldown)
+	first.setErr(nil)
+	if got, _ := fetchFrom(t, c); got != "first" {
+		t.Errorf("answered by %q after the cooldown, want first", got)
+	}
+}
+
+func TestProviderChainTriesUnhealthyWhenAllAre(t *testing.T) {
+	first := &fakeProvider{name: "first", err: errUnavailable}
+	second := &fakeProvider{name: "second", err: errUnavailable}
+	c := NewProviderChain(first, second)
+
+	for range healthMinSamples {
+		fetchFrom(t, c)
+	}
+	if c.health[0].healthy() || c.health[1].healthy() {
+		t.Fatal("both should be cooling down")
+	}
+
+	second.setErr(nil)
+	if got, err := fetchFrom(t, c); got != "second" {
+		t.Errorf("answered by %q, %v; want second, tried despite its cooldown", got, err)
+	}
+}
+
+func TestProviderChainHealth(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		bench bool
+	}{
+		{"server error", errUnavailable, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"invalid key", &APIError{StatusCode: http.StatusUnauthorized}, true},
+		{"forbidden", &APIError{StatusCode: http.StatusForbidden}, true},
+		{"network", errors.New("connection refused"), true},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"not supported", fmt.Errorf("reverse geocoding: %w", ErrNotSupported), false},
+		{"no results", ErrNoResults, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countsAgainstHealth(tt.err); got != tt.bench {
+				t.Errorf("countsAgainstHealth(%v) = %v, want %v", tt.err, got, tt.bench)
+			}
+
+			p := &fakeProvider{name: "only", err: tt.err}
+			c := NewProviderChain(p)
+			for range healthWindow {
+				fetchFrom(t, c)
+			}
+			if benched := !c.health[0].healthy(); benched != tt.bench {
+				t.Errorf("benched after %d failures = %v, want %v", healthWindow, benched, tt.bench)
+			}
+		})
+	}
+}
+
+func TestProviderChainCancelledIsNotAFailure(t *testing.T) {
+	p := &fakeProvider{name: "slow", release: make(chan struct{})}
+	c := NewProviderChain(p)
+
+	for range healthWindow {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := c.FetchWeather(ctx, 1, 2, "en")
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want the caller's deadline", err)
+		}
+	}
+
+	if stats := c.Stats()[0]; stats.Samples != 0 || !c.health[0].healthy() {
+		t.Errorf("giving up on a call was held against the provider: %+v", stats)
+	}
+}
@@ -0,0 +1,95 @@
+package weather
+
+import (
+	"context"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"slices"
+)
+
+// Favorite is a saved location. ID identifies it among the favorites, not
+// among the cities.
+type Favorite struct {
+	ID int64
+	City
+}
+
+// Favorites lists the saved locations in the order the user put them.
+func (s *WeatherService) Favorites(ctx context.Context) ([]Favorite, error) {
+	rows, err := s.DB.ListSavedLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	favorites := make([]Favorite, len(rows))
+	for i, row := range rows {
+		favorites[i] = Favorite{
+			ID: row.ID,
+			City: City{
+				Name:    row.Name,
+				Country: row.Country,
+				State:   row.State,
+				Lat:     row.Lat,
+				Lon:     row.Lon,
+			},
+		}
+	}
+	return favorites, nil
+}
+
+// AddFavorite saves city at the end of the favorites. A city that is already
+// saved keeps its place.
+func (s *WeatherService) AddFavorite(ctx context.Context, city City) error {
+	return s.DB.AddSavedLocation(ctx, database.AddSavedLocationParams{
+		Name:    city.Name,
+		Country: city.Country,
+		State:   city.State,
+		Lat:     city.Lat,
+		Lon:     city.Lon,
+	})
+}
+
+func (s *WeatherService) RemoveFavorite(ctx context.Context, id int64) error {
+	return s.DB.DeleteSavedLocation(ctx, id)
+}
+
+// MoveFavorite moves a favorite by places towards the top of the list (by <
+// 0) or the bottom (by > 0), stopping at either end.
+func (s *WeatherService) MoveFavorite(ctx context.Context, id int64, by int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.DB.WithTx(tx)
+	rows, err := qtx.ListSavedLocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	from := -1
+	for i, row := range rows {
+		if row.ID == id {
+			from = i
+		}
+	}
+	if from < 0 {
+		return nil
+	}
+	to := min(max(from+by, 0), len(rows)-1)
+
+	moved := rows[from]
+	rows = slices.Insert(slices.Delete(rows, from, from+1), to, moved)
+
+	// Renumber them all, which also closes the gaps left by removals
+	for i, row := range rows {
+		err := qtx.SetSavedLocationPosition(ctx, database.SetSavedLocationPositionParams{
+			Position: int64(i + 1),
+			ID:       row.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
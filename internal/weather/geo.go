@@ -0,0 +1,86 @@
+package weather
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	// DefaultCacheRadius is how far from a cached reading a location may be
+	// and still be answered with it.
+	DefaultCacheRadius = 1.5
+)
+
+// haversineKm is the great-circle distance between a and b.
+func haversineKm(a, b Coordinates) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// searchBox is a lat/lon box around every point within some distance of a
+// center. It has two longitude ranges so it can wrap around the antimeridian;
+// when it doesn't, both ranges are the same.
+type searchBox struct {
+	MinLat, MaxLat float64
+	Lon            [2][2]float64
+}
+
+func newSearchBox(center Coordinates, radiusKm float64) searchBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := searchBox{MinLat: center.Lat - dLat, MaxLat: center.Lat + dLat}
+
+	// A circle around a pole covers every longitude
+	if box.MaxLat >= 90 || box.MinLat <= -90 {
+		box.Lon = [2][2]float64{{-180, 180}, {-180, 180}}
+		return box
+	}
+
+	dLon := math.Asin(math.Sin(radiusKm/earthRadiusKm)/math.Cos(center.Lat*math.Pi/180)) * 180 / math.Pi
+	minLon, maxLon := center.Lon-dLon, center.Lon+dLon
+	switch {
+	case minLon < -180:
+		box.Lon = [2][2]float64{{minLon + 360, 180}, {-180, maxLon}}
+	case maxLon > 180:
+		box.Lon = [2][2]float64{{minLon, 180}, {-180, maxLon - 360}}
+	default:
+		box.Lon = [2][2]float64{{minLon, maxLon}, {minLon, maxLon}}
+	}
+	return box
+}
+
+// nearest picks the row closest to center within radiusKm, the freshest one
+// among rows equally close. at tells where and when a row was recorded.
+func nearest[T any](center Coordinates, radiusKm float64, rows []T, at func(T) (Coordinates, int64)) (T, bool) {
+	type candidate struct {
+		row       T
+		distance  float64
+		fetchedAt int64
+	}
+
+	var candidates []candidate
+	for _, row := range rows {
+		coord, fetchedAt := at(row)
+		if d := haversineKm(center, coord); d <= radiusKm {
+			candidates = append(candidates, candidate{row, d, fetchedAt})
+		}
+	}
+
+	var zero T
+	if len(candidates) == 0 {
+		return zero, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].fetchedAt > candidates[j].fetchedAt
+	})
+	return candidates[0].row, true
+}
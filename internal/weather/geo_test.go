@@ -0,0 +1,232 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+// destination is the point km away from c in the direction of bearing,
+// in degrees clockwise from north.
+func destination(c Coordinates, bearing, km float64) Coordinates {
+	lat1, lon1 := c.Lat*math.Pi/180, c.Lon*math.Pi/180
+	b, d := bearing*math.Pi/180, km/earthRadiusKm
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lon2 := lon1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	lon := math.Mod(lon2*180/math.Pi+540, 360) - 180
+	return Coordinates{Lat: lat2 * 180 / math.Pi, Lon: lon}
+}
+
+// contains is what the cache queries ask of a box.
+func (box searchBox) contains(c Coordinates) bool {
+	if c.Lat < box.MinLat || c.Lat > box.MaxLat {
+		return false
+	}
+	for _, r := range box.Lon {
+		if c.Lon >= r[0] && c.Lon <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Coordinates
+		want float64
+	}{
+		{"same point", Coordinates{Lat: 48.85, Lon: 2.35}, Coordinates{Lat: 48.85, Lon: 2.35}, 0},
+		{"across the antimeridian", Coordinates{Lat: 0, Lon: 179.99}, Coordinates{Lat: 0, Lon: -179.99}, 2.224},
+		{"one degree along the equator", Coordinates{Lat: 0, Lon: 0}, Coordinates{Lat: 0, Lon: 1}, 111.195},
+		{"one degree along 70°N", Coordinates{Lat: 70, Lon: 0}, Coordinates{Lat: 70, Lon: 1}, 38.03},
+		{"over the north pole", Coordinates{Lat: 89.99, Lon: 0}, Coordinates{Lat: 89.99, Lon: 180}, 2.224},
+		{"pole to pole", Coordinates{Lat: 90, Lon: 0}, Coordinates{Lat: -90, Lon: 0}, math.Pi * earthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := haversineKm(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("haversineKm(%v, %v) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSearchBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		center   Coordinates
+		radiusKm float64
+		// allLons is set when the box has to cover every longitude
+		allLons bool
+		// inside and outside are points the box must and must not contain
+		inside, outside []Coordinates
+	}{
+		{
+			name:     "east of the antimeridian",
+			center:   Coordinates{Lat: 0, Lon: 179.99},
+			radiusKm: 5,
+			inside:   []Coordinates{{Lat: 0, Lon: -179.99}, {Lat: 0, Lon: 179.98}},
+			outside:  []Coordinates{{Lat: 0, Lon: 0}, {Lat: 0, Lon: -179.9}},
+		},
+		{
+			name:     "west of the antimeridian",
+			center:   Coordinates{Lat: -17.7, Lon: -179.99},
+			radiusKm: 5,
+			inside:   []Coordinates{{Lat: -17.7, Lon: 179.99}},
+			outside:  []Coordinates{{Lat: -17.7, Lon: 179.9}},
+		},
+		{
+			name:     "on the antimeridian",
+			center:   Coordinates{Lat: 65, Lon: 180},
+			radiusKm: 1.5,
+			inside:   []Coordinates{{Lat: 65, Lon: -179.99}, {Lat: 65, Lon: 179.99}},
+		},
+		{
+			name:     "at the north pole",
+			center:   Coordinates{Lat: 90, Lon: 0},
+			radiusKm: 1.5,
+			allLons:  true,
+			inside:   []Coordinates{{Lat: 89.999, Lon: 135}, {Lat: 89.999, Lon: -45}},
+			outside:  []Coordinates{{Lat: 89.9, Lon: 0}},
+		},
+		{
+			name:     "reaching over the south pole",
+			center:   Coordinates{Lat: -89.99, Lon: 45},
+			radiusKm: 5,
+			allLons:  true,
+			inside:   []Coordinates{{Lat: -89.99, Lon: -135}},
+		},
+		{
+			name:     "just short of the north pole",
+			center:   Coordinates{Lat: 89.5, Lon: 10},
+			radiusKm: 50,
+			inside:   []Coordinates{{Lat: 89.5, Lon: 60}},
+			outside:  []Coordinates{{Lat: 89.5, Lon: -170}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := newSearchBox(tt.center, tt.radiusKm)
+			for _, r := range box.Lon {
+				if math.IsNaN(r[0]) || math.IsNaN(r[1]) || r[0] < -180 || r[1] > 180 || r[0] > r[1] {
+					t.Fatalf("bad longitude range %v in %+v", r, box)
+				}
+			}
+			if got := box.Lon[0] == [2]float64{-180, 180}; got != tt.allLons {
+				t.Errorf("box covers every longitude = %v, want %v: %+v", got, tt.allLons, box)
+			}
+
+			// Every point on the circle must be found
+			for bearing := 0.0; bearing < 360; bearing += 7.5 {
+				p := destination(tt.center, bearing, tt.radiusKm*0.999)
+				if !box.contains(p) {
+					t.Errorf("%v at %.1f° is within %.1f km but outside %+v", p, bearing, tt.radiusKm, box)
+				}
+			}
+			for _, p := range tt.inside {
+				if !box.contains(p) {
+					t.Errorf("%v is outside %+v", p, box)
+				}
+			}
+			for _, p := range tt.outside {
+				if box.contains(p) {
+					t.Errorf("%v is inside %+v", p, box)
+				}
+			}
+		})
+	}
+}
+
+func TestNewSearchBoxWidensWithLatitude(t *testing.T) {
+	const radius = 10.0
+	equator := newSearchBox(Coordinates{Lat: 0, Lon: 20}, radius)
+	north := newSearchBox(Coordinates{Lat: 70, Lon: 20}, radius)
+
+	latSpan := func(b searchBox) float64 { return b.MaxLat - b.MinLat }
+	lonSpan := func(b searchBox) float64 { return b.Lon[0][1] - b.Lon[0][0] }
+
+	if math.Abs(latSpan(equator)-latSpan(north)) > 1e-9 {
+		t.Errorf("latitude spans differ: %f at the equator, %f at 70°N", latSpan(equator), latSpan(north))
+	}
+
+	// A degree of longitude at 70°N is cos(70°) as long as at the equator
+	want := 1 / math.Cos(70*math.Pi/180)
+	if got := lonSpan(north) / lonSpan(equator); math.Abs(got-want) > 0.01 {
+		t.Errorf("longitude span at 70°N is %.3f times the equator's, want %.3f", got, want)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	type row struct {
+		name      string
+		coord     Coordinates
+		fetchedAt int64
+	}
+	at := func(r row) (Coordinates, int64) { return r.coord, r.fetchedAt }
+	center := Coordinates{Lat: 48.8566, Lon: 2.3522}
+
+	tests := []struct {
+		name     string
+		center   Coordinates
+		radiusKm float64
+		rows     []row
+		want     string
+	}{
+		{
+			name:     "nearer beats newer",
+			center:   center,
+			radiusKm: 1.5,
+			rows: []row{
+				{"far and new", destination(center, 90, 1.2), 2000},
+				{"near and old", destination(center, 270, 0.3), 1000},
+			},
+			want: "near and old",
+		},
+		{
+			name:     "newer breaks a tie",
+			center:   center,
+			radiusKm: 1.5,
+			rows: []row{
+				{"old", center, 1000},
+				{"new", center, 2000},
+			},
+			want: "new",
+		},
+		{
+			name:     "outside the radius",
+			center:   center,
+			radiusKm: 1.5,
+			rows: []row{
+				{"too far", destination(center, 0, 1.6), 2000},
+			},
+		},
+		{
+			name:     "across the antimeridian",
+			center:   Coordinates{Lat: 0, Lon: 179.99},
+			radiusKm: 5,
+			rows: []row{
+				{"west", Coordinates{Lat: 0, Lon: 179.95}, 2000},
+				{"east", Coordinates{Lat: 0, Lon: -179.99}, 1000},
+			},
+			want: "east",
+		},
+		{
+			name:     "no rows",
+			center:   center,
+			radiusKm: 1.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nearest(tt.center, tt.radiusKm, tt.rows, at)
+			if ok != (tt.want != "") || got.name != tt.want {
+				t.Errorf("nearest = %q, %v; want %q", got.name, ok, tt.want)
+			}
+		})
+	}
+}
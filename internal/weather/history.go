@@ -0,0 +1,78 @@
+package weather
+
+import (
+	"context"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// historyHalfLife is how long it takes a pick to count half as much.
+	historyHalfLife = 7 * 24 * time.Hour
+	// historyCandidates is how many of the latest picks get ranked.
+	historyCandidates = 200
+)
+
+// frecency ranks a place picked count times, the last time age ago, so that
+// both often and recently picked places come first.
+func frecency(count int64, age time.Duration) float64 {
+	return float64(count) * math.Exp2(-max(age, 0).Hours()/historyHalfLife.Hours())
+}
+
+// RecordSelection remembers that city was picked from the search.
+func (s *WeatherService) RecordSelection(city City) {
+	params := database.RecordSearchParams{
+		CityID:     int64(city.Id),
+		Name:       city.Name,
+		Country:    city.Country,
+		State:      city.State,
+		Lat:        city.Lat,
+		Lon:        city.Lon,
+		LastUsedAt: time.Now().Unix(),
+	}
+	s.writer.enqueue("record the search", func(ctx context.Context) error {
+		return s.DB.RecordSearch(ctx, params)
+	})
+}
+
+// RecentCities lists previously picked places whose names start with query,
+// by frecency. An empty query lists them all.
+func (s *WeatherService) RecentCities(ctx context.Context, query string, limit int) ([]City, error) {
+	rows, err := s.DB.ListSearchHistory(ctx, historyCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	type ranked struct {
+		row   database.SearchHistory
+		score float64
+	}
+
+	query = database.NormalizeName(query)
+	now := time.Now()
+	var matches []ranked
+	for _, row := range rows {
+		if strings.HasPrefix(database.NormalizeName(row.Name), query) {
+			matches = append(matches, ranked{row, frecency(row.Count, now.Sub(time.Unix(row.LastUsedAt, 0)))})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	cities := make([]City, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		cities = append(cities, City{
+			Id:      int(m.row.CityID),
+			Name:    m.row.Name,
+			Country: m.row.Country,
+			State:   m.row.State,
+			Lat:     m.row.Lat,
+			Lon:     m.row.Lon,
+		})
+	}
+	return cities, nil
+}
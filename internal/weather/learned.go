@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"hash/fnv"
+)
+
+// syntheticCityID makes up an ID for a geocoded city that came without one.
+// It is negative so it can't collide with a real city ID, and stable so the
+// same place geocoded twice is stored once.
+func syntheticCityID(city City) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%s|%.2f|%.2f", database.NormalizeName(city.Name), city.State, city.Country, city.Lat, city.Lon)
+	return -int64(h.Sum64() & (1<<62 - 1))
+}
+
+// rememberCities stores cities found by the geocoding API, so that the next
+// search for them is answered locally.
+func (s *WeatherService) rememberCities(cities []City) {
+	s.writer.enqueue("remember geocoded cities", func(ctx context.Context) error {
+		var learned []database.City
+		for _, city := range cities {
+			row := database.City{
+				ID:         int64(city.Id),
+				Name:       city.Name,
+				Country:    city.Country,
+				State:      city.State,
+				Lat:        city.Lat,
+				Lon:        city.Lon,
+				SearchName: database.NormalizeName(city.Name),
+				Source:     database.CitySourceGeocoded,
+			}
+			if row.ID == 0 {
+				row.ID = syntheticCityID(city)
+			}
+
+			inserted, err := s.DB.InsertCityIfMissing(ctx, database.InsertCityIfMissingParams{
+				ID:         row.ID,
+				Name:       row.Name,
+				Country:    row.Country,
+				State:      row.State,
+				Lat:        row.Lat,
+				Lon:        row.Lon,
+				SearchName: row.SearchName,
+				Source:     row.Source,
+			})
+			if err != nil {
+				return err
+			}
+			if inserted > 0 {
+				learned = append(learned, row)
+			}
+		}
+
+		if idx := s.cityIndex.Load(); idx != nil && len(learned) > 0 {
+			idx.add(learned...)
+		}
+		return nil
+	})
+}
+
+// LearnedCities lists the cities remembered from the geocoding API, newest
+// first.
+func (s *WeatherService) LearnedCities(ctx context.Context) ([]database.City, error) {
+	return s.DB.ListCitiesBySource(ctx, database.CitySourceGeocoded)
+}
+
+// ForgetCity deletes a city remembered from the geocoding API. It reports
+// false if there is no such learned city; bundled and imported cities are
+// never deleted.
+func (s *WeatherService) ForgetCity(ctx context.Context, id int64) (bool, error) {
+	removed, err := s.DB.DeleteCityBySource(ctx, database.DeleteCityBySourceParams{
+		ID:     id,
+		Source: database.CitySourceGeocoded,
+	})
+	return removed > 0, err
+}
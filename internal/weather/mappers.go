@@ -62,6 +62,8 @@ func (res *WeatherResponse) ToDBWeather() database.InsertWeatherParams {
 		WeatherTime: sql.NullInt64{Int64: res.DT, Valid: true},
 		FetchedAt:   sql.NullInt64{Int64: now, Valid: true},
 		Timezone:    sql.NullInt64{Int64: int64(res.Timezone), Valid: true},
+		Provider:    sql.NullString{String: res.Provider, Valid: res.Provider != ""},
+		Lang:        sql.NullString{String: res.Lang, Valid: true},
 	}
 }
 
@@ -115,10 +117,119 @@ func WeatherCacheToResponse(w database.WeatherCache) WeatherResponse {
 			Deg:   nullInt(w.WindDeg),
 		},
 
-		Rain: nullFloat64(w.Rain1h),
+		Rain:      nullFloat64(w.Rain1h),
+		Provider:  nullString(w.Provider),
+		Lang:      nullString(w.Lang),
+		FetchedAt: nullInt64(w.FetchedAt),
 	}
 }
 
+func (res *ForecastResponse) ToDBForecast() []database.InsertForecastParams {
+	now := time.Now().Unix()
+	rows := make([]database.InsertForecastParams, 0, len(res.List))
+
+	for _, entry := range res.List {
+		var (
+			weatherMain sql.NullString
+			weatherDesc sql.NullString
+			weatherIcon sql.NullString
+			weatherID   sql.NullInt64
+		)
+		if len(entry.Weather) > 0 {
+			weatherMain = sql.NullString{String: entry.Weather[0].Type, Valid: true}
+			weatherDesc = sql.NullString{String: entry.Weather[0].Desc, Valid: true}
+			weatherIcon = sql.NullString{String: entry.Weather[0].Icon, Valid: true}
+			weatherID = sql.NullInt64{Int64: int64(entry.Weather[0].Id), Valid: true}
+		}
+
+		rows = append(rows, database.InsertForecastParams{
+			CityID:       sql.NullInt64{Int64: int64(res.City.ID), Valid: true},
+			CityName:     sql.NullString{String: res.City.Name, Valid: res.City.Name != ""},
+			Country:      sql.NullString{String: res.City.Country, Valid: res.City.Country != ""},
+			Lat:          sql.NullFloat64{Float64: res.City.Coord.Lat, Valid: true},
+			Lon:          sql.NullFloat64{Float64: res.City.Coord.Lon, Valid: true},
+			Timezone:     sql.NullInt64{Int64: int64(res.City.Timezone), Valid: true},
+			ForecastTime: sql.NullInt64{Int64: entry.DT, Valid: true},
+			WeatherMain:  weatherMain,
+			WeatherDesc:  weatherDesc,
+			WeatherIcon:  weatherIcon,
+			WeatherID:    weatherID,
+			Temp:         sql.NullFloat64{Float64: entry.Main.Temp, Valid: true},
+			FeelsLike:    sql.NullFloat64{Float64: entry.Main.FeelsLike, Valid: true},
+			TempMin:      sql.NullFloat64{Float64: entry.Main.TempMin, Valid: true},
+			TempMax:      sql.NullFloat64{Float64: entry.Main.TempMax, Valid: true},
+			Humidity:     sql.NullInt64{Int64: int64(entry.Main.Humidity), Valid: true},
+			Pressure:     sql.NullInt64{Int64: int64(entry.Main.Pressure), Valid: true},
+			WindSpeed:    sql.NullFloat64{Float64: entry.Wind.Speed, Valid: true},
+			WindDeg:      sql.NullInt64{Int64: int64(entry.Wind.Deg), Valid: true},
+			WindGust:     sql.NullFloat64{Float64: entry.Wind.Gust, Valid: entry.Wind.Gust > 0},
+			Pop:          sql.NullFloat64{Float64: entry.Pop, Valid: true},
+			Rain3h:       sql.NullFloat64{Float64: entry.Rain.ThreeHour, Valid: entry.Rain.ThreeHour > 0},
+			Cloudiness:   sql.NullInt64{Int64: int64(entry.Clouds.All), Valid: true},
+			Visibility:   sql.NullInt64{Int64: int64(entry.Vis), Valid: true},
+			FetchedAt:    sql.NullInt64{Int64: now, Valid: true},
+			Lang:         sql.NullString{String: res.Lang, Valid: true},
+		})
+	}
+
+	return rows
+}
+
+func ForecastCacheToResponse(rows []database.ForecastCache) ForecastResponse {
+	var res ForecastResponse
+	if len(rows) == 0 {
+		return res
+	}
+
+	first := rows[0]
+	res.City = ForecastCity{
+		ID:       nullInt(first.CityID),
+		Name:     nullString(first.CityName),
+		Country:  nullString(first.Country),
+		Timezone: nullInt(first.Timezone),
+		Coord: Coordinates{
+			Lat: nullFloat64(first.Lat),
+			Lon: nullFloat64(first.Lon),
+		},
+	}
+
+	res.List = make([]ForecastEntry, 0, len(rows))
+	for _, f := range rows {
+		res.List = append(res.List, ForecastEntry{
+			DT:  nullInt64(f.ForecastTime),
+			Pop: nullFloat64(f.Pop),
+			Vis: nullInt(f.Visibility),
+			Weather: []BasicWeather{
+				{
+					Type: nullString(f.WeatherMain),
+					Desc: nullString(f.WeatherDesc),
+					Icon: nullString(f.WeatherIcon),
+					Id:   nullInt(f.WeatherID),
+				},
+			},
+			Main: MainWeather{
+				Temp:      nullFloat64(f.Temp),
+				FeelsLike: nullFloat64(f.FeelsLike),
+				TempMin:   nullFloat64(f.TempMin),
+				TempMax:   nullFloat64(f.TempMax),
+				Pressure:  nullInt(f.Pressure),
+				Humidity:  nullInt(f.Humidity),
+			},
+			Wind: Wind{
+				Speed: nullFloat64(f.WindSpeed),
+				Gust:  nullFloat64(f.WindGust),
+				Deg:   nullInt(f.WindDeg),
+			},
+			Clouds: Clouds{All: nullInt(f.Cloudiness)},
+			Rain:   ForecastRain{ThreeHour: nullFloat64(f.Rain3h)},
+		})
+	}
+	res.Cnt = len(res.List)
+	res.Lang = nullString(first.Lang)
+
+	return res
+}
+
 func nullString(ns sql.NullString) string {
 	if ns.Valid {
 		return ns.String
@@ -0,0 +1,428 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	OpenMeteoForecastURL  = "https://api.open-meteo.com/v1/forecast"
+	OpenMeteoGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+)
+
+// OpenMeteoClient talks to the keyless Open-Meteo APIs.
+type OpenMeteoClient struct {
+	HTTPClient  *http.Client
+	ForecastURL string
+	GeocoderURL string
+}
+
+func NewOpenMeteoClient() *OpenMeteoClient {
+	return &OpenMeteoClient{
+		HTTPClient:  &http.Client{Timeout: 10 * time.Minute},
+		ForecastURL: OpenMeteoForecastURL,
+		GeocoderURL: OpenMeteoGeocodingURL,
+	}
+}
+
+func (c *OpenMeteoClient) Name() string { return "Open-Meteo" }
+
+type openMeteoCurrent struct {
+	Time          int64   `json:"time"`
+	Temp          float64 `json:"temperature_2m"`
+	Humidity      float64 `json:"relative_humidity_2m"`
+	FeelsLike     float64 `json:"apparent_temperature"`
+	IsDay         int     `json:"is_day"`
+	Rain          float64 `json:"rain"`
+	WeatherCode   int     `json:"weather_code"`
+	CloudCover    float64 `json:"cloud_cover"`
+	PressureMSL   float64 `json:"pressure_msl"`
+	SurfacePress  float64 `json:"surface_pressure"`
+	WindSpeed     float64 `json:"wind_speed_10m"`
+	WindDirection float64 `json:"wind_direction_10m"`
+	WindGusts     float64 `json:"wind_gusts_10m"`
+	Visibility    float64 `json:"visibility"`
+}
+
+type openMeteoDaily struct {
+	Time    []int64   `json:"time"`
+	TempMax []float64 `json:"temperature_2m_max"`
+	TempMin []float64 `json:"temperature_2m_min"`
+	Sunrise []int64   `json:"sunrise"`
+	Sunset  []int64   `json:"sunset"`
+}
+
+type openMeteoHourly struct {
+	Time          []int64   `json:"time"`
+	Temp          []float64 `json:"temperature_2m"`
+	Humidity      []float64 `json:"relative_humidity_2m"`
+	FeelsLike     []float64 `json:"apparent_temperature"`
+	IsDay         []int     `json:"is_day"`
+	Pop           []float64 `json:"precipitation_probability"`
+	Rain          []float64 `json:"rain"`
+	WeatherCode   []int     `json:"weather_code"`
+	CloudCover    []float64 `json:"cloud_cover"`
+	PressureMSL   []float64 `json:"pressure_msl"`
+	Visibility    []float64 `json:"visibility"`
+	WindSpeed     []float64 `json:"wind_speed_10m"`
+	WindDirection []float64 `json:"wind_direction_10m"`
+	WindGusts     []float64 `json:"wind_gusts_10m"`
+}
+
+type openMeteoForecast struct {
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	UTCOffset int              `json:"utc_offset_seconds"`
+	Current   openMeteoCurrent `json:"current"`
+	Daily     openMeteoDaily   `json:"daily"`
+	Hourly    openMeteoHourly  `json:"hourly"`
+}
+
+type openMeteoPlace struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	CountryCode string  `json:"country_code"`
+	Admin1      string  `json:"admin1"`
+}
+
+type openMeteoGeocoding struct {
+	Results []openMeteoPlace `json:"results"`
+}
+
+func (c *OpenMeteoClient) forecastRequest(ctx context.Context, lat, lon float64, params map[string]string) (*openMeteoForecast, error) {
+	forecastUrl, err := url.Parse(c.ForecastURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := forecastUrl.Query()
+	query.Set("latitude", fmt.Sprintf("%f", lat))
+	query.Set("longitude", fmt.Sprintf("%f", lon))
+	query.Set("timezone", "auto")
+	query.Set("timeformat", "unixtime")
+	query.Set("wind_speed_unit", "ms")
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	forecastUrl.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", forecastUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error forming the open-meteo request: %s", err)
+	}
+
+	return FetchAndDecode[openMeteoForecast](c.HTTPClient, req)
+}
+
+func (c *OpenMeteoClient) FetchWeather(ctx context.Context, lat, lon float64, lang string) (*WeatherResponse, error) {
+	res, err := c.forecastRequest(ctx, lat, lon, map[string]string{
+		"current": strings.Join([]string{
+			"temperature_2m", "relative_humidity_2m", "apparent_temperature", "is_day",
+			"rain", "weather_code", "cloud_cover", "pressure_msl", "surface_pressure",
+			"wind_speed_10m", "wind_direction_10m", "wind_gusts_10m", "visibility",
+		}, ","),
+		"daily":         "temperature_2m_max,temperature_2m_min,sunrise,sunset",
+		"forecast_days": "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lang = wmoLanguage(lang)
+	cur := res.Current
+	w := &WeatherResponse{
+		Weather: []BasicWeather{wmoCondition(cur.WeatherCode, cur.IsDay == 1, lang)},
+		Main: MainWeather{
+			Temp:        cur.Temp,
+			FeelsLike:   cur.FeelsLike,
+			TempMin:     cur.Temp,
+			TempMax:     cur.Temp,
+			Pressure:    int(cur.PressureMSL),
+			Humidity:    int(cur.Humidity),
+			SeaLevel:    int(cur.PressureMSL),
+			GroundLevel: int(cur.SurfacePress),
+		},
+		Wind: Wind{
+			Speed: cur.WindSpeed,
+			Gust:  cur.WindGusts,
+			Deg:   int(cur.WindDirection),
+		},
+		Coord:    Coordinates{Lat: res.Latitude, Lon: res.Longitude},
+		Rain:     cur.Rain,
+		Base:     "open-meteo",
+		Provider: c.Name(),
+		Lang:     lang,
+		DT:       cur.Time,
+		COD:      200,
+		Clouds:   int(cur.CloudCover),
+		Timezone: res.UTCOffset,
+		Vis:      int(cur.Visibility),
+	}
+
+	if len(res.Daily.Time) > 0 {
+		w.Main.TempMin = valueAt(res.Daily.TempMin, 0)
+		w.Main.TempMax = valueAt(res.Daily.TempMax, 0)
+		w.Sys.Sunrise = valueAt(res.Daily.Sunrise, 0)
+		w.Sys.Sunset = valueAt(res.Daily.Sunset, 0)
+	}
+
+	return w, nil
+}
+
+func (c *OpenMeteoClient) FetchForecast(ctx context.Context, lat, lon float64, lang string) (*ForecastResponse, error) {
+	res, err := c.forecastRequest(ctx, lat, lon, map[string]string{
+		"hourly": strings.Join([]string{
+			"temperature_2m", "relative_humidity_2m", "apparent_temperature", "is_day",
+			"precipitation_probability", "rain", "weather_code", "cloud_cover",
+			"pressure_msl", "visibility", "wind_speed_10m", "wind_direction_10m", "wind_gusts_10m",
+		}, ","),
+		"forecast_days": "5",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lang = wmoLanguage(lang)
+	h := res.Hourly
+	f := &ForecastResponse{
+		City: ForecastCity{
+			Coord:    Coordinates{Lat: res.Latitude, Lon: res.Longitude},
+			Timezone: res.UTCOffset,
+		},
+		Provider: c.Name(),
+		Lang:     lang,
+	}
+
+	// Sample every third hour so the slots line up with the OpenWeatherMap 3-hour forecast
+	for i := 0; i < len(h.Time); i += 3 {
+		var rain float64
+		for j := i; j < min(i+3, len(h.Rain)); j++ {
+			rain += h.Rain[j]
+		}
+
+		f.List = append(f.List, ForecastEntry{
+			Weather: []BasicWeather{wmoCondition(valueAt(h.WeatherCode, i), valueAt(h.IsDay, i) == 1, lang)},
+			Main: MainWeather{
+				Temp:      valueAt(h.Temp, i),
+				FeelsLike: valueAt(h.FeelsLike, i),
+				TempMin:   valueAt(h.Temp, i),
+				TempMax:   valueAt(h.Temp, i),
+				Pressure:  int(valueAt(h.PressureMSL, i)),
+				Humidity:  int(valueAt(h.Humidity, i)),
+			},
+			Wind: Wind{
+				Speed: valueAt(h.WindSpeed, i),
+				Gust:  valueAt(h.WindGusts, i),
+				Deg:   int(valueAt(h.WindDirection, i)),
+			},
+			Clouds: Clouds{All: int(valueAt(h.CloudCover, i))},
+			Rain:   ForecastRain{ThreeHour: rain},
+			DT:     h.Time[i],
+			Pop:    valueAt(h.Pop, i) / 100,
+			Vis:    int(valueAt(h.Visibility, i)),
+		})
+	}
+	f.Cnt = len(f.List)
+
+	return f, nil
+}
+
+func (c *OpenMeteoClient) FetchGeocoding(ctx context.Context, cityName string, limit int, lang string) ([]City, error) {
+	geocoderUrl, err := url.Parse(c.GeocoderURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := geocoderUrl.Query()
+	query.Set("name", cityName)
+	query.Set("count", fmt.Sprintf("%d", limit))
+	query.Set("format", "json")
+	if lang != "" {
+		query.Set("language", lang)
+	}
+	geocoderUrl.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", geocoderUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error forming the geocoding request: %s", err)
+	}
+
+	res, err := FetchAndDecode[openMeteoGeocoding](c.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Results) == 0 {
+		return nil, fmt.Errorf("no cities found with name %s: %w", cityName, ErrNoResults)
+	}
+
+	cities := make([]City, 0, len(res.Results))
+	for _, place := range res.Results {
+		cities = append(cities, City{
+			Name:    place.Name,
+			Country: place.CountryCode,
+			State:   place.Admin1,
+			Lat:     place.Latitude,
+			Lon:     place.Longitude,
+			Id:      place.ID,
+		})
+	}
+
+	return cities, nil
+}
+
+func (c *OpenMeteoClient) FetchReverseGeocoding(ctx context.Context, coord Coordinates, limit int) ([]City, error) {
+	return nil, fmt.Errorf("%s reverse geocoding: %w", c.Name(), ErrNotSupported)
+}
+
+// valueAt guards against Open-Meteo returning a shorter array for a variable
+// that is missing in the selected weather model.
+func valueAt[T any](values []T, i int) T {
+	var zero T
+	if i < len(values) {
+		return values[i]
+	}
+	return zero
+}
+
+type wmoInfo struct {
+	id   int
+	main string
+	desc string
+	icon string
+}
+
+// wmoCodes maps WMO weather interpretation codes onto the closest
+// OpenWeatherMap condition, so icons and descriptions render the same way.
+var wmoCodes = map[int]wmoInfo{
+	0:  {800, "Clear", "clear sky", "01"},
+	1:  {801, "Clouds", "mainly clear", "02"},
+	2:  {802, "Clouds", "partly cloudy", "03"},
+	3:  {804, "Clouds", "overcast", "04"},
+	45: {741, "Fog", "fog", "50"},
+	48: {741, "Fog", "depositing rime fog", "50"},
+	51: {300, "Drizzle", "light drizzle", "09"},
+	53: {301, "Drizzle", "drizzle", "09"},
+	55: {302, "Drizzle", "dense drizzle", "09"},
+	56: {310, "Drizzle", "light freezing drizzle", "09"},
+	57: {311, "Drizzle", "dense freezing drizzle", "09"},
+	61: {500, "Rain", "slight rain", "10"},
+	63: {501, "Rain", "moderate rain", "10"},
+	65: {502, "Rain", "heavy rain", "10"},
+	66: {511, "Rain", "light freezing rain", "13"},
+	67: {511, "Rain", "heavy freezing rain", "13"},
+	71: {600, "Snow", "slight snow fall", "13"},
+	73: {601, "Snow", "moderate snow fall", "13"},
+	75: {602, "Snow", "heavy snow fall", "13"},
+	77: {611, "Snow", "snow grains", "13"},
+	80: {520, "Rain", "slight rain showers", "09"},
+	81: {521, "Rain", "moderate rain showers", "09"},
+	82: {522, "Rain", "violent rain showers", "09"},
+	85: {620, "Snow", "slight snow showers", "13"},
+	86: {622, "Snow", "heavy snow showers", "13"},
+	95: {211, "Thunderstorm", "thunderstorm", "11"},
+	96: {201, "Thunderstorm", "thunderstorm with slight hail", "11"},
+	99: {202, "Thunderstorm", "thunderstorm with heavy hail", "11"},
+}
+
+// wmoDescriptions translates the descriptions in wmoCodes. Open-Meteo only
+// sends codes, so these are the only languages it can describe weather in.
+var wmoDescriptions = map[string]map[int]string{
+	"es": {
+		0:  "cielo despejado",
+		1:  "mayormente despejado",
+		2:  "parcialmente nublado",
+		3:  "cubierto",
+		45: "niebla",
+		48: "niebla con escarcha",
+		51: "llovizna ligera",
+		53: "llovizna",
+		55: "llovizna densa",
+		56: "llovizna helada ligera",
+		57: "llovizna helada densa",
+		61: "lluvia ligera",
+		63: "lluvia moderada",
+		65: "lluvia fuerte",
+		66: "lluvia helada ligera",
+		67: "lluvia helada fuerte",
+		71: "nevada ligera",
+		73: "nevada moderada",
+		75: "nevada fuerte",
+		77: "granos de nieve",
+		80: "chubascos ligeros",
+		81: "chubascos moderados",
+		82: "chubascos violentos",
+		85: "chubascos de nieve ligeros",
+		86: "chubascos de nieve fuertes",
+		95: "tormenta",
+		96: "tormenta con granizo ligero",
+		99: "tormenta con granizo fuerte",
+	},
+	"de": {
+		0:  "klarer Himmel",
+		1:  "überwiegend klar",
+		2:  "teilweise bewölkt",
+		3:  "bedeckt",
+		45: "Nebel",
+		48: "Nebel mit Reifablagerung",
+		51: "leichter Nieselregen",
+		53: "Nieselregen",
+		55: "dichter Nieselregen",
+		56: "leichter gefrierender Nieselregen",
+		57: "dichter gefrierender Nieselregen",
+		61: "leichter Regen",
+		63: "mäßiger Regen",
+		65: "starker Regen",
+		66: "leichter gefrierender Regen",
+		67: "starker gefrierender Regen",
+		71: "leichter Schneefall",
+		73: "mäßiger Schneefall",
+		75: "starker Schneefall",
+		77: "Schneegriesel",
+		80: "leichte Regenschauer",
+		81: "mäßige Regenschauer",
+		82: "heftige Regenschauer",
+		85: "leichte Schneeschauer",
+		86: "starke Schneeschauer",
+		95: "Gewitter",
+		96: "Gewitter mit leichtem Hagel",
+		99: "Gewitter mit starkem Hagel",
+	},
+}
+
+// wmoLanguage is the language Open-Meteo responses are described in when
+// lang is asked for, and so the one they are cached under.
+func wmoLanguage(lang string) string {
+	if _, ok := wmoDescriptions[lang]; ok {
+		return lang
+	}
+	return "en"
+}
+
+func wmoCondition(code int, isDay bool, lang string) BasicWeather {
+	info, ok := wmoCodes[code]
+	if !ok {
+		info = wmoInfo{800, "Clear", "unknown", "01"}
+	}
+	if desc, ok := wmoDescriptions[lang][code]; ok {
+		info.desc = desc
+	}
+
+	suffix := "n"
+	if isDay {
+		suffix = "d"
+	}
+
+	return BasicWeather{
+		Type: info.main,
+		Desc: info.desc,
+		Icon: info.icon + suffix,
+		Id:   info.id,
+	}
+}
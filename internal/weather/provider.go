@@ -0,0 +1,33 @@
+package weather
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotSupported is returned by providers for calls their API has no equivalent for.
+	ErrNotSupported = errors.New("not supported by this provider")
+	// ErrNoResults means the provider answered, but had nothing for the query.
+	ErrNoResults = errors.New("no results")
+	// ErrOffline is returned in offline mode when the cache has nothing to offer.
+	ErrOffline = errors.New("offline and nothing cached")
+)
+
+// Provider is a source of weather and geocoding data. Every implementation
+// maps its API into the OpenWeatherMap shaped types of this package, in
+// metric units, and stamps its Name and the language the descriptions are
+// actually in on the responses it returns.
+type Provider interface {
+	Name() string
+	FetchWeather(ctx context.Context, lat, lon float64, lang string) (*WeatherResponse, error)
+	FetchForecast(ctx context.Context, lat, lon float64, lang string) (*ForecastResponse, error)
+	FetchGeocoding(ctx context.Context, cityName string, limit int, lang string) ([]City, error)
+	FetchReverseGeocoding(ctx context.Context, coord Coordinates, limit int) ([]City, error)
+}
+
+var (
+	_ Provider = (*WeatherClient)(nil)
+	_ Provider = (*OpenMeteoClient)(nil)
+	_ Provider = (*ProviderChain)(nil)
+)
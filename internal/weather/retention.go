@@ -0,0 +1,131 @@
+package weather
+
+import (
+	"context"
+	"database/sql"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"log"
+	"time"
+)
+
+// RetentionPolicy says how much weather history to keep. Readings younger
+// than FullResolution are all kept, up to Hourly one per hour and spot is
+// kept, and one per day after that. Forecasts are dropped after Forecasts.
+type RetentionPolicy struct {
+	FullResolution time.Duration
+	Hourly         time.Duration
+	Forecasts      time.Duration
+}
+
+var DefaultRetention = RetentionPolicy{
+	FullResolution: 7 * 24 * time.Hour,
+	Hourly:         90 * 24 * time.Hour,
+	Forecasts:      7 * 24 * time.Hour,
+}
+
+// RetentionInterval is how often RunRetention compacts the cache.
+const RetentionInterval = 6 * time.Hour
+
+type PruneResult struct {
+	WeatherRows    int64
+	ForecastRows   int64
+	BytesReclaimed int64
+}
+
+// Compact rolls old weather up to hourly and daily readings and drops old
+// forecasts, as the policy says.
+func (s *WeatherService) Compact(ctx context.Context, policy RetentionPolicy) (PruneResult, error) {
+	var res PruneResult
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.DB.WithTx(tx)
+	now := time.Now()
+
+	tiers := []struct {
+		age    time.Duration
+		bucket time.Duration
+	}{
+		{policy.FullResolution, time.Hour},
+		{policy.Hourly, 24 * time.Hour},
+	}
+	for _, tier := range tiers {
+		removed, err := qtx.CompactWeather(ctx, database.CompactWeatherParams{
+			Before: sql.NullInt64{Int64: now.Add(-tier.age).Unix(), Valid: true},
+			Bucket: int64(tier.bucket.Seconds()),
+		})
+		if err != nil {
+			return res, err
+		}
+		res.WeatherRows += removed
+	}
+
+	res.ForecastRows, err = qtx.DeleteOldForecast(ctx, sql.NullInt64{Int64: now.Add(-policy.Forecasts).Unix(), Valid: true})
+	if err != nil {
+		return res, err
+	}
+
+	return res, tx.Commit()
+}
+
+// Prune compacts the cache and then gives the freed pages back to the file
+// system, which Compact alone doesn't.
+func (s *WeatherService) Prune(ctx context.Context, policy RetentionPolicy) (PruneResult, error) {
+	res, err := s.Compact(ctx, policy)
+	if err != nil {
+		return res, err
+	}
+
+	before, err := s.dbSize(ctx)
+	if err != nil {
+		return res, err
+	}
+	if _, err := s.conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return res, err
+	}
+	after, err := s.dbSize(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	res.BytesReclaimed = before - after
+	return res, nil
+}
+
+func (s *WeatherService) dbSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+	if err := s.conn.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, err
+	}
+	if err := s.conn.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pageCount * pageSize, nil
+}
+
+// RunRetention compacts the cache right away and then every interval, until
+// ctx is done. The compaction runs on the cache writer like any other write.
+func (s *WeatherService) RunRetention(ctx context.Context, policy RetentionPolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.writer.enqueue("compact the cache", func(ctx context.Context) error {
+			res, err := s.Compact(ctx, policy)
+			if err == nil && (res.WeatherRows > 0 || res.ForecastRows > 0) {
+				log.Printf("Compacted the cache: %d weather rows, %d forecast rows removed", res.WeatherRows, res.ForecastRows)
+			}
+			return err
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
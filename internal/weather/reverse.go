@@ -0,0 +1,83 @@
+package weather
+
+import (
+	"context"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"log"
+)
+
+// reverseGeocodeRadius is how far from a point the nearest known city may be
+// and still lend the point its name.
+const reverseGeocodeRadius = 20.0
+
+// ReverseGeocode names the place at coord, from the cities table when a city
+// is close enough and through the provider otherwise, and remembers what the
+// provider found. The city it returns is always at coord itself, so the
+// weather is fetched for that exact point rather than for the city it was
+// named after. Call it once the user settled on the point; NameCoordinates
+// is the one to call while they type.
+func (s *WeatherService) ReverseGeocode(ctx context.Context, coord Coordinates) City {
+	point := City{Name: FormatCoordinates(coord), Lat: coord.Lat, Lon: coord.Lon}
+
+	if city, ok := s.nearestCity(ctx, coord); ok {
+		return atPoint(city, coord)
+	}
+
+	if s.Offline || s.Client == nil {
+		return point
+	}
+
+	cities, err := s.Client.FetchReverseGeocoding(ctx, coord, 1)
+	if err != nil || len(cities) == 0 {
+		log.Printf("no name found for %v: %v", coord, err)
+		return point
+	}
+	s.rememberCities(cities)
+	return atPoint(cities[0], coord)
+}
+
+// NameCoordinates names the place at coord after the nearest known city,
+// or after the coordinates themselves. It never asks the provider.
+func (s *WeatherService) NameCoordinates(ctx context.Context, coord Coordinates) City {
+	if city, ok := s.nearestCity(ctx, coord); ok {
+		return atPoint(city, coord)
+	}
+	return City{Name: FormatCoordinates(coord), Lat: coord.Lat, Lon: coord.Lon}
+}
+
+func (s *WeatherService) nearestCity(ctx context.Context, coord Coordinates) (City, bool) {
+	box := newSearchBox(coord, reverseGeocodeRadius)
+	rows, err := s.DB.FindCitiesInBox(ctx, database.FindCitiesInBoxParams{
+		Lat:   box.MinLat,
+		Lat_2: box.MaxLat,
+		Lon:   box.Lon[0][0],
+		Lon_2: box.Lon[0][1],
+		Lon_3: box.Lon[1][0],
+		Lon_4: box.Lon[1][1],
+	})
+	if err != nil {
+		log.Printf("Failed to look up cities near %v: %s", coord, err)
+		return City{}, false
+	}
+
+	row, ok := nearest(coord, reverseGeocodeRadius, rows, func(c database.City) (Coordinates, int64) {
+		// Among cities equally close, the bigger one
+		return Coordinates{Lat: c.Lat, Lon: c.Lon}, c.Population.Int64
+	})
+	if !ok {
+		return City{}, false
+	}
+	return cityFromRow(row), true
+}
+
+// atPoint names coord after city without moving it to the city.
+func atPoint(city City, coord Coordinates) City {
+	return City{
+		Name:       city.Name,
+		Country:    city.Country,
+		State:      city.State,
+		LocalNames: city.LocalNames,
+		Lat:        coord.Lat,
+		Lon:        coord.Lon,
+	}
+}
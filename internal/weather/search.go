@@ -0,0 +1,138 @@
+package weather
+
+import (
+	"context"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// CityPageSize is how many cities one page of search results holds.
+	CityPageSize = 20
+	// searchCandidates is how many index matches get ranked per search.
+	searchCandidates = 500
+	// minMatchScore is the score below which a name is too unlike the query
+	// to offer, so that a place we don't know finds nothing locally and
+	// ResolveCity asks the API.
+	minMatchScore = 0.25
+)
+
+// trigrams splits a normalized name into its distinct windows of three runes,
+// the way the cities_fts index tokenizes search_name.
+func trigrams(s string) map[string]struct{} {
+	runes := []rune(s)
+	grams := make(map[string]struct{})
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = struct{}{}
+	}
+	return grams
+}
+
+// matchExpression asks the index for every name sharing at least one trigram
+// with the query, so a typo only costs the trigrams it touches.
+func matchExpression(grams map[string]struct{}) string {
+	terms := make([]string, 0, len(grams))
+	for g := range grams {
+		terms = append(terms, `"`+strings.ReplaceAll(g, `"`, `""`)+`"`)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, " OR ")
+}
+
+// matchScore ranks a name by the share of trigrams it has in common with the
+// query, names starting with the query first, nudged towards bigger places
+// when the population is known.
+func matchScore(shared, queryGrams, nameGrams int, prefix bool, population int64) float64 {
+	score := 0.0
+	if union := queryGrams + nameGrams - shared; union > 0 {
+		score = float64(shared) / float64(union)
+	}
+	if prefix {
+		score++
+	}
+	if population > 0 {
+		score *= 1 + 0.05*math.Log10(float64(population))
+	}
+	return score
+}
+
+func cityScore(query string, grams map[string]struct{}, city database.City) float64 {
+	nameGrams := trigrams(city.SearchName)
+	shared := 0
+	for g := range grams {
+		if _, ok := nameGrams[g]; ok {
+			shared++
+		}
+	}
+	return matchScore(shared, len(grams), len(nameGrams), strings.HasPrefix(city.SearchName, query), city.Population.Int64)
+}
+
+func (s *WeatherService) rankedCities(ctx context.Context, query string) ([]database.City, error) {
+	query = database.NormalizeName(query)
+	grams := trigrams(query)
+	if len(grams) == 0 {
+		// Too short for the trigram index; a prefix match is the best there is
+		return s.DB.FuzzYFindCity(ctx, query+"%")
+	}
+
+	rows, err := s.DB.SearchCities(ctx, database.SearchCitiesParams{
+		Query:      matchExpression(grams),
+		MaxResults: searchCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int64]float64, len(rows))
+	matches := rows[:0]
+	for _, row := range rows {
+		if score := cityScore(query, grams, row); score >= minMatchScore {
+			scores[row.ID] = score
+			matches = append(matches, row)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return scores[matches[i].ID] > scores[matches[j].ID]
+	})
+	return matches, nil
+}
+
+// SearchCities finds cities whose names look like query, typos included,
+// best matches first. limit and offset page through the results. Once the
+// in-memory index is loaded it answers instead of the database.
+func (s *WeatherService) SearchCities(ctx context.Context, query string, limit, offset int) ([]City, error) {
+	if idx := s.cityIndex.Load(); idx != nil {
+		if cities, ok := idx.search(query, limit, offset); ok {
+			return cities, nil
+		}
+	}
+
+	rows, err := s.rankedCities(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset >= len(rows) {
+		return nil, nil
+	}
+	rows = rows[offset:min(offset+limit, len(rows))]
+
+	cities := make([]City, len(rows))
+	for i, row := range rows {
+		cities[i] = cityFromRow(row)
+	}
+	return cities, nil
+}
+
+func cityFromRow(row database.City) City {
+	return City{
+		Id:      int(row.ID),
+		Name:    row.Name,
+		Country: row.Country,
+		State:   row.State,
+		Lat:     row.Lat,
+		Lon:     row.Lon,
+	}
+}
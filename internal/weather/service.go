@@ -6,93 +6,362 @@ import (
 	"fmt"
 	"github/Arnab-cloud/tui_weather_app/internal/database"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type WeatherService struct {
 	DB     *database.Queries
-	Client *WeatherClient
+	Client Provider
+	Lang   string
+	// Offline serves everything from the cache, however old, and never
+	// touches the network.
+	Offline bool
+	// CacheRadius is how close, in km, a cached reading has to be to count
+	// as the weather at the asked-for location.
+	CacheRadius float64
+	conn        *sql.DB
+
+	weatherFlights  flightGroup[*WeatherResponse]
+	forecastFlights flightGroup[*ForecastResponse]
+	cityFlights     flightGroup[[]City]
+
+	updates    chan WeatherUpdate
+	refreshing sync.Map
+	writer     *cacheWriter
+	cityIndex  atomic.Pointer[cityIndex]
+}
+
+// WeatherUpdate is fresh weather for a spot that was earlier answered from an
+// aging cache row.
+type WeatherUpdate struct {
+	Coord   Coordinates
+	Weather *WeatherResponse
 }
 
 const (
-	EPSILON       = 0.01
-	CacheDuration = 10 * time.Minute
+	// Cached weather younger than CacheDuration is served as is. Up to
+	// HardCacheDuration it is still served, but refreshed in the background.
+	CacheDuration     = 10 * time.Minute
+	HardCacheDuration = 2 * time.Hour
+
+	// geocodingCandidates is how many places of the same name to ask the
+	// geocoding API for.
+	geocodingCandidates = 5
 )
 
-func NewWeatherService(conn *sql.DB, client *WeatherClient) *WeatherService {
+func NewWeatherService(conn *sql.DB, client Provider, lang string) *WeatherService {
 	return &WeatherService{
-		DB:     database.New(conn),
-		Client: client,
+		DB:          database.New(conn),
+		Client:      client,
+		Lang:        lang,
+		CacheRadius: DefaultCacheRadius,
+		conn:        conn,
+		updates:     make(chan WeatherUpdate, 8),
+		writer:      newCacheWriter(),
 	}
 }
 
+// Close waits for pending cache writes. The service must not be used after.
+func (s *WeatherService) Close() {
+	s.writer.close()
+}
+
+// Updates delivers the results of background refreshes started by GetWeather.
+func (s *WeatherService) Updates() <-chan WeatherUpdate {
+	return s.updates
+}
+
+// GetWeather returns the current weather at loc, from the cache when it is
+// recent enough. A cached answer older than CacheDuration is refreshed in the
+// background and the new one sent on Updates. Concurrent calls for the same
+// spot share one lookup.
 func (s *WeatherService) GetWeather(ctx context.Context, loc Location) (*WeatherResponse, error) {
-	var w *WeatherResponse
-	if loc.Coord.Lat == 0 && loc.Coord.Lon == 0 {
-		cities, err := s.ResolveCity(ctx, loc.Name)
-		if err != nil {
-			return nil, err
-		}
-		loc.Coord = Coordinates{Lat: cities[0].Lat, Lon: cities[0].Lon}
+	loc, err := s.resolveLocation(ctx, loc)
+	if err != nil {
+		return nil, err
 	}
 
+	return s.weatherFlights.Do(coordKey(loc.Coord), func() (*WeatherResponse, error) {
+		// Shared with other callers, so one of them giving up must not cancel it
+		return s.getWeather(context.WithoutCancel(ctx), loc)
+	})
+}
+
+func (s *WeatherService) getWeather(ctx context.Context, loc Location) (*WeatherResponse, error) {
+	box := newSearchBox(loc.Coord, s.CacheRadius)
 	cacheParams := database.GetFreshWeatherByCoordsParams{
-		Lat:       sql.NullFloat64{Float64: loc.Coord.Lat - EPSILON, Valid: true},
-		Lat_2:     sql.NullFloat64{Float64: loc.Coord.Lat + EPSILON, Valid: true},
-		Lon:       sql.NullFloat64{Float64: loc.Coord.Lon - EPSILON, Valid: true},
-		Lon_2:     sql.NullFloat64{Float64: loc.Coord.Lon + EPSILON, Valid: true},
-		FetchedAt: sql.NullInt64{Int64: time.Now().Add(-10 * time.Minute).Unix(), Valid: true},
+		Lat:       sql.NullFloat64{Float64: box.MinLat, Valid: true},
+		Lat_2:     sql.NullFloat64{Float64: box.MaxLat, Valid: true},
+		Lon:       sql.NullFloat64{Float64: box.Lon[0][0], Valid: true},
+		Lon_2:     sql.NullFloat64{Float64: box.Lon[0][1], Valid: true},
+		Lon_3:     sql.NullFloat64{Float64: box.Lon[1][0], Valid: true},
+		Lon_4:     sql.NullFloat64{Float64: box.Lon[1][1], Valid: true},
+		FetchedAt: sql.NullInt64{Int64: time.Now().Add(-HardCacheDuration).Unix(), Valid: true},
+		Lang:      sql.NullString{String: s.Lang, Valid: true},
+	}
+
+	if s.Offline {
+		return s.staleWeather(ctx, loc, ErrOffline)
 	}
 
-	if cached, err := s.DB.GetFreshWeatherByCoords(ctx, cacheParams); err == nil {
+	rows, err := s.DB.GetFreshWeatherByCoords(ctx, cacheParams)
+	if err != nil {
+		log.Printf("Failed to look up cached weather: %s", err)
+	}
+	if cached, ok := s.nearestWeather(loc.Coord, rows); ok {
 		cachedWeatherRes := WeatherCacheToResponse(cached)
+		if cachedWeatherRes.Age() > CacheDuration {
+			s.revalidate(loc)
+		}
 		return &cachedWeatherRes, nil
 	}
 
-	w, err := s.Client.FetchWeather(ctx, loc.Coord.Lat, loc.Coord.Lon)
+	w, err := s.fetchWeather(ctx, loc)
 	if err != nil {
-		return nil, err
+		return s.staleWeather(ctx, loc, err)
+	}
+	return w, nil
+}
+
+// revalidate refreshes the weather at loc in the background, at most once
+// per spot at a time, and sends the result on Updates.
+func (s *WeatherService) revalidate(loc Location) {
+	key := coordKey(loc.Coord)
+	if _, running := s.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
 	}
 
-	func() {
-		if err := s.DB.InsertWeather(ctx, w.ToDBWeather()); err != nil {
-			log.Printf("Failed to cache the response: %s", err)
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		w, err := s.fetchWeather(context.Background(), loc)
+		if err != nil {
+			log.Printf("background refresh for %v failed: %s", loc.Coord, err)
+			return
+		}
+
+		select {
+		case s.updates <- WeatherUpdate{Coord: loc.Coord, Weather: w}:
+		default:
+			log.Printf("dropping background refresh for %v, nobody is listening", loc.Coord)
 		}
 	}()
+}
+
+// fetchWeather gets the weather at loc from the provider and caches it.
+func (s *WeatherService) fetchWeather(ctx context.Context, loc Location) (*WeatherResponse, error) {
+	w, err := s.Client.FetchWeather(ctx, loc.Coord.Lat, loc.Coord.Lon, s.Lang)
+	if err != nil {
+		return nil, err
+	}
+	w.FetchedAt = time.Now().Unix()
+
+	// Not every provider knows the name of the place it was asked about
+	if w.Name == "" {
+		w.Name = loc.Name
+	}
+	if w.Sys.Country == "" {
+		w.Sys.Country = loc.Country
+	}
+
+	// Cached where and in the language it was asked for, not where the
+	// provider says it is, which for a gridded model can be further off than
+	// the cache radius, nor the language it fell back to, which is never
+	// looked up
+	row := w.ToDBWeather()
+	row.Lat = sql.NullFloat64{Float64: loc.Coord.Lat, Valid: true}
+	row.Lon = sql.NullFloat64{Float64: loc.Coord.Lon, Valid: true}
+	row.Lang = sql.NullString{String: s.Lang, Valid: true}
+	s.writer.enqueue("cache the weather", func(ctx context.Context) error {
+		return s.DB.InsertWeather(ctx, row)
+	})
 
 	return w, nil
 }
 
-func (s *WeatherService) ResolveCity(ctx context.Context, name string) ([]City, error) {
-	var cities []City
-	query := name + "%"
-	dbCities, err := s.DB.FuzzYFindCity(ctx, query)
+// staleWeather falls back to the newest cached row of any age. fetchErr is
+// what went wrong getting fresh data, returned if the cache is empty too.
+func (s *WeatherService) staleWeather(ctx context.Context, loc Location, fetchErr error) (*WeatherResponse, error) {
+	box := newSearchBox(loc.Coord, s.CacheRadius)
+	rows, err := s.DB.GetLatestWeatherByCoords(ctx, database.GetLatestWeatherByCoordsParams{
+		Lat:       sql.NullFloat64{Float64: box.MinLat, Valid: true},
+		Lat_2:     sql.NullFloat64{Float64: box.MaxLat, Valid: true},
+		Lon:       sql.NullFloat64{Float64: box.Lon[0][0], Valid: true},
+		Lon_2:     sql.NullFloat64{Float64: box.Lon[0][1], Valid: true},
+		Lon_3:     sql.NullFloat64{Float64: box.Lon[1][0], Valid: true},
+		Lon_4:     sql.NullFloat64{Float64: box.Lon[1][1], Valid: true},
+		FetchedAt: sql.NullInt64{Int64: 0, Valid: true},
+	})
+	cached, ok := s.nearestWeather(loc.Coord, rows)
+	if err != nil || !ok {
+		return nil, fetchErr
+	}
+
+	log.Printf("serving stale weather for %v: %s", loc.Coord, fetchErr)
+	stale := WeatherCacheToResponse(cached)
+	stale.Stale = true
+	return &stale, nil
+}
+
+func (s *WeatherService) nearestWeather(coord Coordinates, rows []database.WeatherCache) (database.WeatherCache, bool) {
+	return nearest(coord, s.CacheRadius, rows, func(w database.WeatherCache) (Coordinates, int64) {
+		return Coordinates{Lat: w.Lat.Float64, Lon: w.Lon.Float64}, w.FetchedAt.Int64
+	})
+}
+
+func (s *WeatherService) GetForecast(ctx context.Context, loc Location) (*ForecastResponse, error) {
+	loc, err := s.resolveLocation(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.forecastFlights.Do(coordKey(loc.Coord), func() (*ForecastResponse, error) {
+		return s.getForecast(context.WithoutCancel(ctx), loc)
+	})
+}
+
+func (s *WeatherService) getForecast(ctx context.Context, loc Location) (*ForecastResponse, error) {
+	maxAge := time.Now().Add(-CacheDuration).Unix()
+	if s.Offline {
+		maxAge = 0
+	}
+
+	box := newSearchBox(loc.Coord, s.CacheRadius)
+	fetchParams := database.GetForecastFetchesParams{
+		Lat:       sql.NullFloat64{Float64: box.MinLat, Valid: true},
+		Lat_2:     sql.NullFloat64{Float64: box.MaxLat, Valid: true},
+		Lon:       sql.NullFloat64{Float64: box.Lon[0][0], Valid: true},
+		Lon_2:     sql.NullFloat64{Float64: box.Lon[0][1], Valid: true},
+		Lon_3:     sql.NullFloat64{Float64: box.Lon[1][0], Valid: true},
+		Lon_4:     sql.NullFloat64{Float64: box.Lon[1][1], Valid: true},
+		FetchedAt: sql.NullInt64{Int64: maxAge, Valid: true},
+		Lang:      sql.NullString{String: s.Lang, Valid: true},
+	}
 
-	log.Printf("db queried")
+	fetches, err := s.DB.GetForecastFetches(ctx, fetchParams)
+	if err != nil {
+		log.Printf("Failed to look up cached forecasts: %s", err)
+	}
+	fetch, ok := nearest(loc.Coord, s.CacheRadius, fetches, func(f database.GetForecastFetchesRow) (Coordinates, int64) {
+		return Coordinates{Lat: f.Lat.Float64, Lon: f.Lon.Float64}, f.FetchedAt.Int64
+	})
+	if ok {
+		rows, err := s.DB.GetForecastByFetch(ctx, database.GetForecastByFetchParams{
+			Lat:       fetch.Lat,
+			Lon:       fetch.Lon,
+			FetchedAt: fetch.FetchedAt,
+		})
+		if err == nil && len(rows) > 0 {
+			cachedForecast := ForecastCacheToResponse(rows)
+			return &cachedForecast, nil
+		}
+	}
+
+	if s.Offline {
+		return nil, ErrOffline
+	}
 
+	f, err := s.Client.FetchForecast(ctx, loc.Coord.Lat, loc.Coord.Lon, s.Lang)
 	if err != nil {
-		log.Printf("fuzzyfind Error: %v", err)
-	}
-
-	if err == nil && len(dbCities) > 0 {
-		for _, dbCity := range dbCities {
-			cities = append(cities, City{
-				Id:      int(dbCity.ID),
-				Name:    dbCity.Name,
-				Country: dbCity.Country,
-				Lat:     dbCity.Lat,
-				Lon:     dbCity.Lon,
-			})
+		return nil, err
+	}
+
+	if f.City.Name == "" {
+		f.City.Name = loc.Name
+	}
+	if f.City.Country == "" {
+		f.City.Country = loc.Country
+	}
+
+	s.writer.enqueue("cache the forecast", func(ctx context.Context) error {
+		return s.cacheForecast(ctx, f, loc.Coord)
+	})
+
+	return f, nil
+}
+
+// cacheForecast stores f under coord, the point it was asked for, and the
+// language it was asked in, like fetchWeather does.
+func (s *WeatherService) cacheForecast(ctx context.Context, f *ForecastResponse, coord Coordinates) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.DB.WithTx(tx)
+	for _, row := range f.ToDBForecast() {
+		row.Lat = sql.NullFloat64{Float64: coord.Lat, Valid: true}
+		row.Lon = sql.NullFloat64{Float64: coord.Lon, Valid: true}
+		row.Lang = sql.NullString{String: s.Lang, Valid: true}
+		if err := qtx.InsertForecast(ctx, row); err != nil {
+			return err
 		}
+	}
+
+	return tx.Commit()
+}
+
+// resolveLocation fills in the coordinates of a location that only has a name.
+func (s *WeatherService) resolveLocation(ctx context.Context, loc Location) (Location, error) {
+	if loc.Coord.Lat == 0 && loc.Coord.Lon == 0 {
+		cities, err := s.ResolveCity(ctx, loc.Name)
+		if err != nil {
+			return loc, err
+		}
+		loc.Coord = Coordinates{Lat: cities[0].Lat, Lon: cities[0].Lon}
+	}
+	return loc, nil
+}
+
+// ResolveCity finds cities by name, locally first and through the provider's
+// geocoding otherwise, and remembers what the provider found. It is meant for
+// names the user submitted; SuggestCities is the one to call while they type.
+// Concurrent calls for the same name share one lookup.
+func (s *WeatherService) ResolveCity(ctx context.Context, name string) ([]City, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	return s.cityFlights.Do(key, func() ([]City, error) {
+		return s.resolveCity(context.WithoutCancel(ctx), name)
+	})
+}
+
+// SuggestCities finds cities by name as it is being typed. It only searches
+// locally, so half-typed names neither cost API calls nor get learned.
+func (s *WeatherService) SuggestCities(ctx context.Context, name string) ([]City, error) {
+	if coord, ok := ParseCoordinates(name); ok {
+		return []City{s.NameCoordinates(ctx, coord)}, nil
+	}
+	return s.SearchCities(ctx, name, CityPageSize, 0)
+}
+
+func (s *WeatherService) resolveCity(ctx context.Context, name string) ([]City, error) {
+	// A point typed in is used as is, whether or not there is a city there
+	if coord, ok := ParseCoordinates(name); ok {
+		return []City{s.ReverseGeocode(ctx, coord)}, nil
+	}
+
+	cities, err := s.SearchCities(ctx, name, CityPageSize, 0)
+	if err != nil {
+		log.Printf("city search Error: %v", err)
+	}
+
+	if err == nil && len(cities) > 0 {
 		return cities, nil
 	}
 
-	cities, err = s.Client.FetchGeocoding(ctx, name, 1)
-	log.Printf("api queried")
+	if s.Offline {
+		return nil, fmt.Errorf("city '%s' not found locally: %w", name, ErrOffline)
+	}
+
+	cities, err = s.Client.FetchGeocoding(ctx, name, geocodingCandidates, s.Lang)
 	if err != nil || len(cities) == 0 {
 		log.Printf("city '%s' not found locally or via API", name)
 		return nil, fmt.Errorf("city '%s' not found locally or via API", name)
 	}
 
+	s.rememberCities(cities)
 	return cities, nil
 }
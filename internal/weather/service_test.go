@@ -0,0 +1,172 @@
+package weather
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// fakeProvider answers every call with made-up weather and counts the calls.
+type fakeProvider struct {
+	name string
+	// offset moves the point it answers for away from the one asked for,
+	// like a model that snaps to its grid
+	offset float64
+	// lang, if set, is the language it answers in whatever is asked for
+	lang string
+	// release, if set, holds every weather call until it is closed
+	release chan struct{}
+
+	mu            sync.Mutex
+	err           error
+	weatherCalls  int
+	forecastCalls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *fakeProvider) calls() (weather, forecast int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.weatherCalls, p.forecastCalls
+}
+
+func (p *fakeProvider) answerLang(lang string) string {
+	if p.lang != "" {
+		return p.lang
+	}
+	return lang
+}
+
+func (p *fakeProvider) FetchWeather(ctx context.Context, lat, lon float64, lang string) (*WeatherResponse, error) {
+	p.mu.Lock()
+	p.weatherCalls++
+	err := p.err
+	p.mu.Unlock()
+
+	if p.release != nil {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &WeatherResponse{
+		Weather:  []BasicWeather{{Type: "Clear", Desc: "clear sky", Icon: "01d", Id: 800}},
+		Main:     MainWeather{Temp: 20, Humidity: 50, Pressure: 1013},
+		Coord:    Coordinates{Lat: lat + p.offset, Lon: lon + p.offset},
+		Name:     "Fakeville",
+		Provider: p.name,
+		Lang:     p.answerLang(lang),
+	}, nil
+}
+
+func (p *fakeProvider) FetchForecast(ctx context.Context, lat, lon float64, lang string) (*ForecastResponse, error) {
+	p.mu.Lock()
+	p.forecastCalls++
+	err := p.err
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return &ForecastResponse{
+		City: ForecastCity{Name: "Fakeville", Coord: Coordinates{Lat: lat + p.offset, Lon: lon + p.offset}},
+		List: []ForecastEntry{{
+			DT:      1760000000,
+			Weather: []BasicWeather{{Type: "Clear", Desc: "clear sky", Icon: "01d", Id: 800}},
+			Main:    MainWeather{Temp: 20},
+		}},
+		Provider: p.name,
+		Lang:     p.answerLang(lang),
+	}, nil
+}
+
+func (p *fakeProvider) FetchGeocoding(ctx context.Context, cityName string, limit int, lang string) ([]City, error) {
+	return nil, ErrNoResults
+}
+
+func (p *fakeProvider) FetchReverseGeocoding(ctx context.Context, coord Coordinates, limit int) ([]City, error) {
+	return nil, ErrNoResults
+}
+
+// flushWrites waits for the cache writes queued so far.
+func flushWrites(s *WeatherService) {
+	done := make(chan struct{})
+	s.writer.enqueue("flush", func(context.Context) error {
+		close(done)
+		return nil
+	})
+	<-done
+}
+
+// lookUpTwice gets the weather and forecast at loc twice, letting the first
+// answers reach the cache in between, and returns how often p was called.
+func lookUpTwice(t *testing.T, s *WeatherService, p *fakeProvider, loc Location) (weather, forecast int) {
+	t.Helper()
+	ctx := context.Background()
+	for range 2 {
+		if _, err := s.GetWeather(ctx, loc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetForecast(ctx, loc); err != nil {
+			t.Fatal(err)
+		}
+		flushWrites(s)
+	}
+	return p.calls()
+}
+
+func TestCacheIsKeptWhereItWasAskedFor(t *testing.T) {
+	// Further off than the cache radius
+	p := &fakeProvider{name: "grid", offset: 0.03}
+	s := newTestService(t)
+	s.Client = p
+
+	weather, forecast := lookUpTwice(t, s, p, Location{Name: "Paris", Coord: Coordinates{Lat: 48.8566, Lon: 2.3522}})
+	if weather != 1 || forecast != 1 {
+		t.Errorf("provider called %d times for the weather and %d for the forecast, want once each", weather, forecast)
+	}
+}
+
+func TestCacheIsKeptInTheLanguageAskedFor(t *testing.T) {
+	// A provider without French falls back to English
+	p := &fakeProvider{name: "english", lang: "en"}
+	s := newTestService(t)
+	s.Client = p
+	s.Lang = "fr"
+
+	weather, forecast := lookUpTwice(t, s, p, Location{Name: "Paris", Coord: Coordinates{Lat: 48.8566, Lon: 2.3522}})
+	if weather != 1 || forecast != 1 {
+		t.Errorf("provider called %d times for the weather and %d for the forecast, want once each", weather, forecast)
+	}
+}
+
+func TestNullIslandIsAPlace(t *testing.T) {
+	p := &fakeProvider{name: "fake"}
+	s := newTestService(t)
+	s.Client = p
+
+	// A point typed in at 0,0, which is also what a location without
+	// coordinates has
+	loc := Location{Name: FormatCoordinates(Coordinates{}), Coord: Coordinates{}}
+	w, err := s.GetWeather(context.Background(), loc)
+	if err != nil {
+		t.Fatalf("GetWeather at 0,0: %s", err)
+	}
+	if w.Coord != (Coordinates{}) {
+		t.Errorf("weather for %v, want 0,0", w.Coord)
+	}
+	if _, err := s.GetForecast(context.Background(), loc); err != nil {
+		t.Fatalf("GetForecast at 0,0: %s", err)
+	}
+}
@@ -2,6 +2,8 @@ package weather
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 type Coordinates struct {
@@ -42,9 +44,10 @@ type WeatherSys struct {
 }
 
 type Location struct {
-	Name  string      `json:"name"`
-	Coord Coordinates `json:"coord"`
-	Id    int         `json:"id"`
+	Name    string      `json:"name"`
+	Country string      `json:"country"`
+	Coord   Coordinates `json:"coord"`
+	Id      int         `json:"id"`
 }
 
 type WeatherResponse struct {
@@ -62,18 +65,140 @@ type WeatherResponse struct {
 	Clouds   int            `json:"clouds.all"`
 	Timezone int            `json:"timezone"`
 	Vis      int            `json:"visibility"`
+	Provider string         `json:"-"`
+	Lang     string         `json:"-"`
+
+	// FetchedAt is when the data left the provider (unix time). Stale is set
+	// when it is served from an old cache row because no fresh data could be had.
+	FetchedAt int64 `json:"-"`
+	Stale     bool  `json:"-"`
+}
+
+// Age is how old the data is.
+func (res *WeatherResponse) Age() time.Duration {
+	return time.Since(time.Unix(res.FetchedAt, 0))
+}
+
+// LocalTime is the unix time t on the clocks where the weather is.
+func (res *WeatherResponse) LocalTime(t int64) time.Time {
+	return time.Unix(t, 0).In(time.FixedZone("", res.Timezone))
+}
+
+type Clouds struct {
+	All int `json:"all"`
+}
+
+type ForecastRain struct {
+	ThreeHour float64 `json:"3h"`
+}
+
+type ForecastEntry struct {
+	Weather []BasicWeather `json:"weather"`
+	Main    MainWeather    `json:"main"`
+	Wind    Wind           `json:"wind"`
+	Clouds  Clouds         `json:"clouds"`
+	Rain    ForecastRain   `json:"rain"`
+	DT      int64          `json:"dt"`
+	Pop     float64        `json:"pop"`
+	Vis     int            `json:"visibility"`
+}
+
+type ForecastCity struct {
+	Coord    Coordinates `json:"coord"`
+	Name     string      `json:"name"`
+	Country  string      `json:"country"`
+	ID       int         `json:"id"`
+	Timezone int         `json:"timezone"`
+	Sunrise  int64       `json:"sunrise"`
+	Sunset   int64       `json:"sunset"`
+}
+
+type ForecastResponse struct {
+	List     []ForecastEntry `json:"list"`
+	City     ForecastCity    `json:"city"`
+	Cnt      int             `json:"cnt"`
+	Provider string          `json:"-"`
+	Lang     string          `json:"-"`
+}
+
+// DailyForecast is one calendar day (in the city's local time) summarised
+// from the 3-hour forecast slots.
+type DailyForecast struct {
+	Date    time.Time
+	TempMin float64
+	TempMax float64
+	Pop     float64
+	Icon    string
+	Desc    string
+}
+
+// Daily groups the forecast slots by local day. The icon and description
+// come from the slot closest to midday.
+func (f *ForecastResponse) Daily() []DailyForecast {
+	loc := time.FixedZone("", f.City.Timezone)
+	var days []DailyForecast
+	bestDist := 0
+
+	for _, entry := range f.List {
+		t := time.Unix(entry.DT, 0).In(loc)
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		dist := abs(t.Hour() - 12)
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, DailyForecast{
+				Date:    date,
+				TempMin: entry.Main.TempMin,
+				TempMax: entry.Main.TempMax,
+			})
+			bestDist = 24
+		}
+
+		day := &days[len(days)-1]
+		day.TempMin = min(day.TempMin, entry.Main.TempMin)
+		day.TempMax = max(day.TempMax, entry.Main.TempMax)
+		day.Pop = max(day.Pop, entry.Pop)
+
+		if dist < bestDist && len(entry.Weather) > 0 {
+			day.Icon = entry.Weather[0].Icon
+			day.Desc = entry.Weather[0].Desc
+			bestDist = dist
+		}
+	}
+
+	return days
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
 }
 
 type City struct {
-	Name    string  `json:"name"`
-	Country string  `json:"country"`
-	Lat     float64 `json:"lat"`
-	Lon     float64 `json:"lon"`
-	Id      int     `json:"id"`
+	LocalNames map[string]string `json:"local_names"`
+	Name       string            `json:"name"`
+	Country    string            `json:"country"`
+	State      string            `json:"state"`
+	Lat        float64           `json:"lat"`
+	Lon        float64           `json:"lon"`
+	Id         int               `json:"id"`
 }
 
 func (city City) Title() string { return city.Name }
 func (city City) Description() string {
-	return fmt.Sprintf("%s, Lat: %f, Lon: %f", city.Country, city.Lat, city.Lon)
+	return fmt.Sprintf("%s · Lat: %f, Lon: %f", city.Place(), city.Lat, city.Lon)
+}
+
+// Place names the city with its state and country, e.g. "Springfield,
+// Illinois, US", to tell apart places of the same name.
+func (city City) Place() string {
+	parts := []string{city.Name}
+	for _, part := range []string{city.State, city.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 func (city City) FilterValue() string { return city.Name }
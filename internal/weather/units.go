@@ -0,0 +1,113 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CanonicalUnits is what every provider is asked for and what the cache
+// stores: °C, m/s, metres and hPa. Conversion to the user's unit system only
+// happens at display time, through UnitSystem.
+const CanonicalUnits = "metric"
+
+type UnitSystem int
+
+const (
+	Metric UnitSystem = iota
+	Imperial
+	Standard
+)
+
+var unitSystemNames = []string{"metric", "imperial", "standard"}
+
+func ParseUnitSystem(name string) (UnitSystem, error) {
+	for i, n := range unitSystemNames {
+		if strings.EqualFold(name, n) {
+			return UnitSystem(i), nil
+		}
+	}
+	return Metric, fmt.Errorf("unknown unit system: %s", name)
+}
+
+func (u UnitSystem) String() string {
+	if int(u) < len(unitSystemNames) {
+		return unitSystemNames[u]
+	}
+	return unitSystemNames[Metric]
+}
+
+// Next cycles metric → imperial → standard → metric.
+func (u UnitSystem) Next() UnitSystem {
+	return (u + 1) % UnitSystem(len(unitSystemNames))
+}
+
+func (u UnitSystem) Temp(celsius float64) float64 {
+	switch u {
+	case Imperial:
+		return celsius*9/5 + 32
+	case Standard:
+		return celsius + 273.15
+	default:
+		return celsius
+	}
+}
+
+func (u UnitSystem) TempSymbol() string {
+	switch u {
+	case Imperial:
+		return "°F"
+	case Standard:
+		return "K"
+	default:
+		return "°C"
+	}
+}
+
+func (u UnitSystem) Speed(metersPerSec float64) float64 {
+	if u == Imperial {
+		return metersPerSec * 2.236936
+	}
+	return metersPerSec
+}
+
+func (u UnitSystem) SpeedSymbol() string {
+	if u == Imperial {
+		return "mph"
+	}
+	return "m/s"
+}
+
+func (u UnitSystem) Distance(meters float64) float64 {
+	if u == Imperial {
+		return meters / 1609.344
+	}
+	return meters / 1000
+}
+
+func (u UnitSystem) DistanceSymbol() string {
+	if u == Imperial {
+		return "mi"
+	}
+	return "km"
+}
+
+func (u UnitSystem) FormatTemp(celsius float64) string {
+	return fmt.Sprintf("%.1f%s", u.Temp(celsius), u.TempSymbol())
+}
+
+// FormatTempShort rounds to whole degrees and only marks them as degrees,
+// "21°", where space is short. Kelvin are not degrees: "294K".
+func (u UnitSystem) FormatTempShort(celsius float64) string {
+	if u == Standard {
+		return fmt.Sprintf("%.0f%s", u.Temp(celsius), u.TempSymbol())
+	}
+	return fmt.Sprintf("%.0f°", u.Temp(celsius))
+}
+
+func (u UnitSystem) FormatSpeed(metersPerSec float64) string {
+	return fmt.Sprintf("%.1f %s", u.Speed(metersPerSec), u.SpeedSymbol())
+}
+
+func (u UnitSystem) FormatDistance(meters float64) string {
+	return fmt.Sprintf("%.1f %s", u.Distance(meters), u.DistanceSymbol())
+}
@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitConversions(t *testing.T) {
+	tests := []struct {
+		units    UnitSystem
+		celsius  float64
+		temp     float64
+		speed    float64 // of 10 m/s
+		distance float64 // of 10000 m
+	}{
+		{Metric, 0, 0, 10, 10},
+		{Metric, -40, -40, 10, 10},
+		{Imperial, 0, 32, 22.36936, 6.213712},
+		{Imperial, 100, 212, 22.36936, 6.213712},
+		{Imperial, -40, -40, 22.36936, 6.213712},
+		{Standard, 0, 273.15, 10, 10},
+		{Standard, -273.15, 0, 10, 10},
+		{Standard, 26.85, 300, 10, 10},
+	}
+
+	for _, tt := range tests {
+		if got := tt.units.Temp(tt.celsius); math.Abs(got-tt.temp) > 1e-6 {
+			t.Errorf("%s Temp(%v) = %v, want %v", tt.units, tt.celsius, got, tt.temp)
+		}
+		if got := tt.units.Speed(10); math.Abs(got-tt.speed) > 1e-5 {
+			t.Errorf("%s Speed(10) = %v, want %v", tt.units, got, tt.speed)
+		}
+		if got := tt.units.Distance(10000); math.Abs(got-tt.distance) > 1e-6 {
+			t.Errorf("%s Distance(10000) = %v, want %v", tt.units, got, tt.distance)
+		}
+	}
+}
+
+func TestUnitFormatting(t *testing.T) {
+	tests := []struct {
+		units                          UnitSystem
+		temp, tempShort, speed, length string
+	}{
+		{Metric, "21.5°C", "22°", "3.0 m/s", "10.0 km"},
+		{Imperial, "70.7°F", "71°", "6.7 mph", "6.2 mi"},
+		{Standard, "294.6K", "295K", "3.0 m/s", "10.0 km"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.units.FormatTemp(21.5); got != tt.temp {
+			t.Errorf("%s FormatTemp(21.5) = %q, want %q", tt.units, got, tt.temp)
+		}
+		if got := tt.units.FormatTempShort(21.5); got != tt.tempShort {
+			t.Errorf("%s FormatTempShort(21.5) = %q, want %q", tt.units, got, tt.tempShort)
+		}
+		if got := tt.units.FormatSpeed(3); got != tt.speed {
+			t.Errorf("%s FormatSpeed(3) = %q, want %q", tt.units, got, tt.speed)
+		}
+		if got := tt.units.FormatDistance(10000); got != tt.length {
+			t.Errorf("%s FormatDistance(10000) = %q, want %q", tt.units, got, tt.length)
+		}
+	}
+}
+
+func TestParseUnitSystem(t *testing.T) {
+	for _, u := range []UnitSystem{Metric, Imperial, Standard} {
+		got, err := ParseUnitSystem(u.String())
+		if err != nil || got != u {
+			t.Errorf("ParseUnitSystem(%q) = %v, %v", u.String(), got, err)
+		}
+	}
+	if got, err := ParseUnitSystem("IMPERIAL"); err != nil || got != Imperial {
+		t.Errorf("ParseUnitSystem(\"IMPERIAL\") = %v, %v", got, err)
+	}
+	if _, err := ParseUnitSystem("kelvin"); err == nil {
+		t.Error("ParseUnitSystem(\"kelvin\") should fail")
+	}
+	if got := Standard.Next(); got != Metric {
+		t.Errorf("Standard.Next() = %v, want metric", got)
+	}
+}
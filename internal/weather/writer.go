@@ -0,0 +1,70 @@
+package weather
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// writeQueueSize is how many cache writes may wait for the writer before new
+// ones are dropped.
+const writeQueueSize = 64
+
+type writeJob struct {
+	what string
+	fn   func(context.Context) error
+}
+
+// cacheWriter runs every cache write on one goroutine, so SQLite only ever
+// sees one writer from this process and callers never wait for a lock.
+type cacheWriter struct {
+	jobs chan writeJob
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newCacheWriter() *cacheWriter {
+	w := &cacheWriter{
+		jobs: make(chan writeJob, writeQueueSize),
+		done: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *cacheWriter) run() {
+	defer close(w.done)
+	for job := range w.jobs {
+		if err := job.fn(context.Background()); err != nil {
+			log.Printf("Failed to %s: %s", job.what, err)
+		}
+	}
+}
+
+// enqueue hands fn to the writer without blocking. A cache write is only an
+// optimization, so when the queue is full it is dropped.
+func (w *cacheWriter) enqueue(what string, fn func(context.Context) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.jobs <- writeJob{what: what, fn: fn}:
+	default:
+		log.Printf("cache writer is behind, skipping: %s", what)
+	}
+}
+
+// close waits for the queued writes to finish.
+func (w *cacheWriter) close() {
+	w.mu.Lock()
+	w.closed = true
+	close(w.jobs)
+	w.mu.Unlock()
+
+	<-w.done
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"fmt"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
 	"github/Arnab-cloud/tui_weather_app/internal/ui"
 	"github/Arnab-cloud/tui_weather_app/internal/weather"
 	"log"
@@ -11,14 +13,16 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/joho/godotenv"
-	_ "modernc.org/sqlite"
 )
 
 var VERSION = "dev"
 
 func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	offline := flag.Bool("offline", false, "serve cached weather only, never touch the network")
+	flag.Parse()
 
-	if len(os.Args) > 1 && os.Args[1] == "--version" {
+	if *showVersion {
 		fmt.Print(VERSION)
 		return
 	}
@@ -41,17 +45,64 @@ func main() {
 		log.Fatalf("Critical error setting up database: %v", err)
 	}
 
-	conn, err := sql.Open("sqlite", dbPath)
+	conn, err := database.Open(dbPath)
 	if err != nil {
-		log.Fatalf("Error opening the logfile: %s", err)
+		log.Fatalf("Error opening the database: %s", err)
 	}
 	defer conn.Close()
 
-	client := weather.NewWeatherClient(GetEnvVariables())
+	args := flag.Args()
 
-	service := weather.NewWeatherService(conn, client)
+	// db subcommands manage migrations themselves
+	if len(args) == 0 || args[0] != "db" {
+		if err := migrateUp(conn); err != nil {
+			log.Fatalf("Error migrating the database: %s", err)
+		}
+	}
+
+	if len(args) > 0 {
+		if err := runCommand(conn, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	lang := GetLanguage()
+	// Offline there is nothing to call, so no API settings are needed either
+	var provider weather.Provider
+	if !*offline {
+		provider = GetProvider()
+	}
+	service := weather.NewWeatherService(conn, provider, lang)
+	defer service.Close()
+	service.Offline = *offline
+	service.CacheRadius = GetCacheRadius()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.RunRetention(ctx, GetRetentionPolicy(), weather.RetentionInterval)
+	go func() {
+		if err := service.LoadCityIndex(ctx); err != nil {
+			log.Printf("Failed to load the city index, searching the database instead: %s", err)
+		}
+	}()
 
-	if _, err := tea.NewProgram(ui.NewModel(service), tea.WithAltScreen()).Run(); err != nil {
+	sessionPath := filepath.Join(appDir, "session.json")
+	session, err := ui.LoadSession(sessionPath)
+	if err != nil {
+		log.Printf("Failed to restore the last session: %s", err)
+	}
+
+	model := ui.NewModel(service, GetUnitSystem(), lang).Restore(session)
+	final, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+	if err != nil {
 		log.Fatalf("Error: %s", err)
 	}
+
+	if m, ok := final.(ui.StateModel); ok {
+		if err := m.Session().Save(sessionPath); err != nil {
+			log.Printf("Failed to save the session: %s", err)
+		}
+	}
 }
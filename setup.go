@@ -3,18 +3,28 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"database/sql"
 	"fmt"
+	"github/Arnab-cloud/tui_weather_app/internal/database"
+	"github/Arnab-cloud/tui_weather_app/internal/weather"
+	"github/Arnab-cloud/tui_weather_app/sql/schema"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "embed"
 )
 
 var (
+	PROVIDER      string = ""
 	GEOCODING_API string = ""
 	WEATHER_API   string = ""
+	FORECAST_API  string = ""
 	API_KEY       string = ""
 )
 
@@ -69,7 +79,124 @@ func ensureDatabase(appDir string) (string, error) {
 	return dbPath, nil
 }
 
-func GetEnvVariables() (ApiKey, WeatherApi, GeocodingApi string) {
+// migrateUp brings the database schema up to date with sql/schema, then
+// fills in the columns a migration added but only Go code can compute.
+func migrateUp(conn *sql.DB) error {
+	ctx := context.Background()
+	migrator, err := database.NewMigrator(conn, schema.FS)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		return err
+	}
+
+	filled, err := database.NewCitySeeder(conn).BackfillSearchNames(ctx, 1000)
+	if filled > 0 {
+		log.Printf("Normalized the search names of %d cities", filled)
+	}
+	return err
+}
+
+// GetProvider builds the weather providers listed in PROVIDER, comma
+// separated, as a failover chain tried in that order. OpenWeatherMap is the
+// default; Open-Meteo needs no API key.
+func GetProvider() weather.Provider {
+	names := PROVIDER
+	if names == "" {
+		names = os.Getenv("PROVIDER")
+	}
+	if names == "" {
+		names = "openweathermap"
+	}
+
+	var providers []weather.Provider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "openweathermap", "owm":
+			providers = append(providers, weather.NewWeatherClient(GetEnvVariables()))
+		case "open-meteo", "openmeteo":
+			providers = append(providers, weather.NewOpenMeteoClient())
+		default:
+			log.Fatalf("Unknown weather provider: %s", name)
+		}
+	}
+
+	return weather.NewProviderChain(providers...)
+}
+
+// GetUnitSystem reads the display units from UNITS: metric (default),
+// imperial or standard.
+func GetUnitSystem() weather.UnitSystem {
+	name := os.Getenv("UNITS")
+	if name == "" {
+		return weather.Metric
+	}
+
+	units, err := weather.ParseUnitSystem(name)
+	if err != nil {
+		log.Printf("%s, falling back to metric", err)
+	}
+	return units
+}
+
+// GetCacheRadius reads from CACHE_RADIUS_KM how far, in km, a cached reading
+// may be from a location and still be used for it.
+func GetCacheRadius() float64 {
+	value := os.Getenv("CACHE_RADIUS_KM")
+	if value == "" {
+		return weather.DefaultCacheRadius
+	}
+
+	radius, err := strconv.ParseFloat(value, 64)
+	if err != nil || radius <= 0 {
+		log.Printf("invalid CACHE_RADIUS_KM %q, falling back to %.1f km", value, weather.DefaultCacheRadius)
+		return weather.DefaultCacheRadius
+	}
+	return radius
+}
+
+// GetRetentionPolicy reads how many days of weather history to keep at full
+// resolution (CACHE_FULL_DAYS) and hourly (CACHE_HOURLY_DAYS).
+func GetRetentionPolicy() weather.RetentionPolicy {
+	policy := weather.DefaultRetention
+	policy.FullResolution = envDays("CACHE_FULL_DAYS", policy.FullResolution)
+	policy.Hourly = envDays("CACHE_HOURLY_DAYS", policy.Hourly)
+	return policy
+}
+
+func envDays(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Printf("invalid %s %q, keeping the default", name, value)
+		return fallback
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetLanguage returns the two-letter language for weather descriptions and
+// UI strings, from WEATHER_LANG or else the language part of LANG.
+func GetLanguage() string {
+	lang := os.Getenv("WEATHER_LANG")
+	if lang == "" {
+		// LANG looks like "de_DE.UTF-8"
+		lang, _, _ = strings.Cut(os.Getenv("LANG"), "_")
+		lang, _, _ = strings.Cut(lang, ".")
+	}
+
+	lang = strings.ToLower(lang)
+	if lang == "" || lang == "c" || lang == "posix" {
+		return "en"
+	}
+	return lang
+}
+
+func GetEnvVariables() (ApiKey, WeatherApi, ForecastApi, GeocodingApi string) {
 	if API_KEY == "" {
 		ApiKey = os.Getenv("API_KEY")
 	} else {
@@ -82,6 +209,12 @@ func GetEnvVariables() (ApiKey, WeatherApi, GeocodingApi string) {
 		WeatherApi = WEATHER_API
 	}
 
+	if FORECAST_API == "" {
+		ForecastApi = os.Getenv("FORECAST_API")
+	} else {
+		ForecastApi = FORECAST_API
+	}
+
 	if GEOCODING_API == "" {
 		GeocodingApi = os.Getenv("GEOCODING_API")
 	} else {
@@ -97,6 +230,10 @@ func GetEnvVariables() (ApiKey, WeatherApi, GeocodingApi string) {
 	if GeocodingApi == "" {
 		log.Fatal("GeocodingApi is missing")
 	}
+	if ForecastApi == "" {
+		// The forecast endpoint lives next to the current weather one
+		ForecastApi = strings.TrimSuffix(WeatherApi, "/weather") + "/forecast"
+	}
 
 	return
 }
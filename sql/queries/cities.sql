@@ -1,12 +1,23 @@
 -- name: CreateCity :one
-INSERT INTO cities (id, name, country, lat, lon)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO cities (id, name, country, lat, lon, population, search_name)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: DeleteCity :exec
 DELETE FROM cities
 WHERE id = ?;
 
+-- name: DeleteCityBySource :execrows
+DELETE FROM cities
+WHERE id = ?
+  AND source = ?;
+
+-- name: FindCitiesInBox :many
+SELECT *
+FROM cities
+WHERE lat >= ? AND lat <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?));
+
 -- name: FindCity :many
 SELECT *
 FROM cities
@@ -21,4 +32,51 @@ WHERE id = ?;
 -- name: FuzzYFindCity :many
 SELECT *
 FROM cities
-WHERE name like ?;
+WHERE search_name LIKE ?;
+
+-- name: InsertCityIfMissing :execrows
+INSERT INTO cities (id, name, country, state, lat, lon, search_name, source)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: ListCities :many
+SELECT *
+FROM cities;
+
+-- name: ListCitiesBySource :many
+SELECT *
+FROM cities
+WHERE source = ?
+ORDER BY created_at DESC, id;
+
+-- name: ListCitiesMissingSearchName :many
+SELECT id, name
+FROM cities
+WHERE search_name = ''
+LIMIT ?;
+
+-- name: SearchCities :many
+SELECT cities.id, cities.name, cities.country, cities.lat, cities.lon, cities.created_at, cities.population, cities.search_name, cities.source, cities.state
+FROM cities_fts
+JOIN cities ON cities.id = cities_fts.rowid
+WHERE cities_fts MATCH CAST(sqlc.arg(query) AS TEXT)
+ORDER BY cities_fts.rank
+LIMIT sqlc.arg(max_results);
+
+-- name: SetCitySearchName :exec
+UPDATE cities
+SET search_name = ?
+WHERE id = ?;
+
+-- name: UpsertCity :exec
+INSERT INTO cities (id, name, country, state, lat, lon, population, search_name, source)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    country = excluded.country,
+    lat = excluded.lat,
+    lon = excluded.lon,
+    population = excluded.population,
+    search_name = excluded.search_name,
+    source = excluded.source,
+    state = COALESCE(NULLIF(excluded.state, ''), state);
@@ -0,0 +1,16 @@
+-- name: GetCityImport :one
+SELECT *
+FROM city_imports
+WHERE source = ?;
+
+-- name: SaveCityImport :exec
+INSERT INTO city_imports (source, size, records_done, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (source) DO UPDATE SET
+    size = excluded.size,
+    records_done = excluded.records_done,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteCityImport :exec
+DELETE FROM city_imports
+WHERE source = ?;
@@ -0,0 +1,53 @@
+-- name: GetForecastFetches :many
+SELECT DISTINCT lat, lon, fetched_at
+FROM forecast_cache
+WHERE lat >= ?
+  AND lat <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
+  AND fetched_at >= ?
+  AND lang = ?
+ORDER BY fetched_at DESC;
+
+-- name: GetForecastByFetch :many
+SELECT *
+FROM forecast_cache
+WHERE lat = ?
+  AND lon = ?
+  AND fetched_at = ?
+ORDER BY forecast_time ASC;
+
+-- name: InsertForecast :exec
+INSERT INTO forecast_cache (
+    city_id,
+    city_name,
+    country,
+    lat,
+    lon,
+    timezone,
+    forecast_time,
+    weather_main,
+    weather_desc,
+    weather_icon,
+    weather_id,
+    temp,
+    feels_like,
+    temp_min,
+    temp_max,
+    humidity,
+    pressure,
+    wind_speed,
+    wind_deg,
+    wind_gust,
+    pop,
+    rain_3h,
+    cloudiness,
+    visibility,
+    fetched_at,
+    lang
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteOldForecast :execrows
+DELETE FROM forecast_cache
+WHERE fetched_at < ?;
@@ -0,0 +1,18 @@
+-- name: AddSavedLocation :exec
+INSERT INTO saved_locations (name, country, state, lat, lon, position)
+VALUES (?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM saved_locations))
+ON CONFLICT (lat, lon) DO NOTHING;
+
+-- name: DeleteSavedLocation :exec
+DELETE FROM saved_locations
+WHERE id = ?;
+
+-- name: ListSavedLocations :many
+SELECT *
+FROM saved_locations
+ORDER BY position, id;
+
+-- name: SetSavedLocationPosition :exec
+UPDATE saved_locations
+SET position = ?
+WHERE id = ?;
@@ -0,0 +1,16 @@
+-- name: ListSearchHistory :many
+SELECT *
+FROM search_history
+ORDER BY last_used_at DESC
+LIMIT ?;
+
+-- name: RecordSearch :exec
+INSERT INTO search_history (city_id, name, country, state, lat, lon, count, last_used_at)
+VALUES (?, ?, ?, ?, ?, ?, 1, ?)
+ON CONFLICT (lat, lon) DO UPDATE SET
+    city_id = excluded.city_id,
+    name = excluded.name,
+    country = excluded.country,
+    state = excluded.state,
+    count = search_history.count + 1,
+    last_used_at = excluded.last_used_at;
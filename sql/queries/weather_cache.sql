@@ -13,27 +13,24 @@ WHERE city_name = ?
 ORDER BY fetched_at DESC
 LIMIT 1;
 
--- name: GetLatestWeatherByCoords :one
+-- name: GetLatestWeatherByCoords :many
 SELECT *
 FROM weather_cache
 WHERE lat >= ?
   AND lat <= ?
-  AND lon >= ?
-  AND lon <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
   AND fetched_at >= ?
-ORDER BY fetched_at DESC
-LIMIT 1;
+ORDER BY fetched_at DESC;
 
--- name: GetFreshWeatherByCoords :one
+-- name: GetFreshWeatherByCoords :many
 SELECT *
 FROM weather_cache
 WHERE lat >= ?
   AND lat <= ?
-  AND lon >= ?
-  AND lon <= ?
+  AND ((lon >= ? AND lon <= ?) OR (lon >= ? AND lon <= ?))
   AND fetched_at >= ?
-ORDER BY fetched_at DESC
-LIMIT 1;
+  AND lang = ?
+ORDER BY fetched_at DESC;
 
 
 -- name: GetLatestWeatherByCityID :one
@@ -73,9 +70,11 @@ INSERT INTO weather_cache (
     sunset,
     weather_time,
     fetched_at,
-    timezone
+    timezone,
+    provider,
+    lang
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 
@@ -91,6 +90,18 @@ WHERE id NOT IN (
     GROUP BY city_id
 );
 
+-- name: CompactWeather :execrows
+-- Keeps only the newest row per spot, language and bucket of time among the
+-- rows fetched before the cutoff.
+DELETE FROM weather_cache
+WHERE fetched_at < sqlc.arg(before)
+  AND id NOT IN (
+    SELECT MAX(id)
+    FROM weather_cache
+    WHERE fetched_at < sqlc.arg(before)
+    GROUP BY ROUND(lat, 2), ROUND(lon, 2), lang, fetched_at / CAST(sqlc.arg(bucket) AS INTEGER)
+  );
+
 -- name: GetWeatherHistoryByCity :many
 SELECT *
 FROM weather_cache
@@ -2,4 +2,4 @@
 CREATE INDEX city_name_idx ON cities (name);
 
 -- +goose Down
-DROP INDEX city_name_idx FROM cities;
+DROP INDEX city_name_idx;
@@ -5,7 +5,7 @@ CREATE INDEX idx_weather_coords ON weather_cache(lat, lon);
 CREATE INDEX idx_weather_fetched ON weather_cache(fetched_at);
 
 -- +goose Down
-DROP INDEX idx_weather_fetched FROM weather_cache;
-DROP INDEX idx_weather_coords FROM weather_cache;
-DROP INDEX idx_weather_city FROM weather_cache;
-DROP INDEX idx_weather_cid FROM weather_cache;
+DROP INDEX idx_weather_fetched;
+DROP INDEX idx_weather_coords;
+DROP INDEX idx_weather_city;
+DROP INDEX idx_weather_cid;
@@ -13,8 +13,8 @@ ALTER TABLE weather_cache ADD COLUMN sunset INTEGER;
 
 -- +goose Down
 
-ALTER TABLE weather_cache DROP COLUMN sunset INTEGER;
-ALTER TABLE weather_cache DROP COLUMN sunrise INTEGER;
-ALTER TABLE weather_cache DROP COLUMN ground_level INTEGER;
-ALTER TABLE weather_cache DROP COLUMN sea_level INTEGER;
-ALTER TABLE weather_cache DROP COLUMN weather_id INTEGER;
+ALTER TABLE weather_cache DROP COLUMN sunset;
+ALTER TABLE weather_cache DROP COLUMN sunrise;
+ALTER TABLE weather_cache DROP COLUMN ground_level;
+ALTER TABLE weather_cache DROP COLUMN sea_level;
+ALTER TABLE weather_cache DROP COLUMN weather_id;
@@ -0,0 +1,51 @@
+-- +goose Up
+CREATE TABLE forecast_cache (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Location
+    city_id INTEGER,
+    city_name TEXT,
+    country TEXT,
+    lat REAL,
+    lon REAL,
+    timezone INTEGER,
+
+    -- Forecast slot (`list[].dt` from API)
+    forecast_time INTEGER,
+
+    -- Weather summary
+    weather_main TEXT,
+    weather_desc TEXT,
+    weather_icon TEXT,
+    weather_id INTEGER,
+
+    -- Temperature
+    temp REAL,
+    feels_like REAL,
+    temp_min REAL,
+    temp_max REAL,
+    humidity INTEGER,
+    pressure INTEGER,
+
+    -- Wind
+    wind_speed REAL,
+    wind_deg INTEGER,
+    wind_gust REAL,
+
+    -- Precipitation
+    pop REAL,
+    rain_3h REAL,
+
+    cloudiness INTEGER,
+    visibility INTEGER,
+
+    fetched_at INTEGER
+);
+
+CREATE INDEX idx_forecast_coords ON forecast_cache(lat, lon);
+CREATE INDEX idx_forecast_fetched ON forecast_cache(fetched_at);
+
+-- +goose Down
+DROP INDEX idx_forecast_fetched;
+DROP INDEX idx_forecast_coords;
+DROP TABLE forecast_cache;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE weather_cache ADD COLUMN provider TEXT;
+
+-- +goose Down
+ALTER TABLE weather_cache DROP COLUMN provider;
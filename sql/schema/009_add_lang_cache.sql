@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE weather_cache ADD COLUMN lang TEXT;
+ALTER TABLE forecast_cache ADD COLUMN lang TEXT;
+
+-- Everything cached so far was fetched without a lang, which the API answers in English
+UPDATE weather_cache SET lang = 'en';
+UPDATE forecast_cache SET lang = 'en';
+
+-- +goose Down
+ALTER TABLE forecast_cache DROP COLUMN lang;
+ALTER TABLE weather_cache DROP COLUMN lang;
@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE cities ADD COLUMN population INTEGER;
+
+-- Trigram index over city names, for typo-tolerant matches anywhere in a name
+CREATE VIRTUAL TABLE cities_fts USING fts5(
+    name,
+    content = 'cities',
+    content_rowid = 'id',
+    tokenize = 'trigram'
+);
+
+INSERT INTO cities_fts (cities_fts) VALUES ('rebuild');
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_insert AFTER INSERT ON cities BEGIN
+    INSERT INTO cities_fts (rowid, name) VALUES (new.id, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_delete AFTER DELETE ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, name) VALUES ('delete', old.id, old.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_update AFTER UPDATE OF name ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, name) VALUES ('delete', old.id, old.name);
+    INSERT INTO cities_fts (rowid, name) VALUES (new.id, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER cities_fts_update;
+DROP TRIGGER cities_fts_delete;
+DROP TRIGGER cities_fts_insert;
+DROP TABLE cities_fts;
+ALTER TABLE cities DROP COLUMN population;
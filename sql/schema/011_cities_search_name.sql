@@ -0,0 +1,77 @@
+-- +goose Up
+-- Name folded for matching: diacritics removed and case folded, so "Sao Paulo"
+-- finds "São Paulo". Filled in by the app, which has the Unicode tables.
+ALTER TABLE cities ADD COLUMN search_name TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_cities_search_name ON cities (search_name);
+
+-- Index the folded name instead of the raw one
+DROP TRIGGER cities_fts_update;
+DROP TRIGGER cities_fts_delete;
+DROP TRIGGER cities_fts_insert;
+DROP TABLE cities_fts;
+
+CREATE VIRTUAL TABLE cities_fts USING fts5(
+    search_name,
+    content = 'cities',
+    content_rowid = 'id',
+    tokenize = 'trigram'
+);
+
+-- Every row needs an entry, even an empty one, for the update trigger to replace
+INSERT INTO cities_fts (cities_fts) VALUES ('rebuild');
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_insert AFTER INSERT ON cities BEGIN
+    INSERT INTO cities_fts (rowid, search_name) VALUES (new.id, new.search_name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_delete AFTER DELETE ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, search_name) VALUES ('delete', old.id, old.search_name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_update AFTER UPDATE OF search_name ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, search_name) VALUES ('delete', old.id, old.search_name);
+    INSERT INTO cities_fts (rowid, search_name) VALUES (new.id, new.search_name);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER cities_fts_update;
+DROP TRIGGER cities_fts_delete;
+DROP TRIGGER cities_fts_insert;
+DROP TABLE cities_fts;
+
+CREATE VIRTUAL TABLE cities_fts USING fts5(
+    name,
+    content = 'cities',
+    content_rowid = 'id',
+    tokenize = 'trigram'
+);
+
+INSERT INTO cities_fts (cities_fts) VALUES ('rebuild');
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_insert AFTER INSERT ON cities BEGIN
+    INSERT INTO cities_fts (rowid, name) VALUES (new.id, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_delete AFTER DELETE ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, name) VALUES ('delete', old.id, old.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER cities_fts_update AFTER UPDATE OF name ON cities BEGIN
+    INSERT INTO cities_fts (cities_fts, rowid, name) VALUES ('delete', old.id, old.name);
+    INSERT INTO cities_fts (rowid, name) VALUES (new.id, new.name);
+END;
+-- +goose StatementEnd
+
+DROP INDEX idx_cities_search_name;
+ALTER TABLE cities DROP COLUMN search_name;
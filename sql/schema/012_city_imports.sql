@@ -0,0 +1,11 @@
+-- +goose Up
+-- Where an interrupted import-cities run left off, so it can resume
+CREATE TABLE city_imports (
+    source TEXT PRIMARY KEY,
+    size INTEGER NOT NULL,
+    records_done INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE city_imports;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Where a city came from: the bundled database, import-cities, or a
+-- geocoding lookup the app remembered
+ALTER TABLE cities ADD COLUMN source TEXT NOT NULL DEFAULT 'bundled';
+CREATE INDEX idx_cities_source ON cities (source);
+
+-- +goose Down
+DROP INDEX idx_cities_source;
+ALTER TABLE cities DROP COLUMN source;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- State, province or region, to tell apart places of the same name
+ALTER TABLE cities ADD COLUMN state TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE cities DROP COLUMN state;
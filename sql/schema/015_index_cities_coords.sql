@@ -0,0 +1,6 @@
+-- +goose Up
+-- For finding the city nearest to a point
+CREATE INDEX idx_cities_lat_lon ON cities (lat, lon);
+
+-- +goose Down
+DROP INDEX idx_cities_lat_lon;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Favorites, in the order the user put them
+CREATE TABLE saved_locations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL DEFAULT '',
+    lat REAL NOT NULL,
+    lon REAL NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (lat, lon)
+);
+
+-- +goose Down
+DROP TABLE saved_locations;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Places picked from the search, for ranking by frecency
+CREATE TABLE search_history (
+    city_id INTEGER NOT NULL DEFAULT 0,
+    name TEXT NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL DEFAULT '',
+    lat REAL NOT NULL,
+    lon REAL NOT NULL,
+    count INTEGER NOT NULL DEFAULT 1,
+    last_used_at INTEGER NOT NULL,
+    PRIMARY KEY (lat, lon)
+);
+
+-- +goose Down
+DROP TABLE search_history;
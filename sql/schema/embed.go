@@ -0,0 +1,7 @@
+// Package schema holds the goose-style migrations that build the database.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS